@@ -3,6 +3,9 @@ package ccloud
 import (
 	"fmt"
 	"log"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
@@ -197,3 +200,96 @@ func serverV2StateRefreshFunc(client *gophercloud.ServiceClient, instanceID stri
 		return s, s.Status, nil
 	}
 }
+
+// arcAgentV1FactsFilterRe matches the facts filter expressions, e.g.
+// facts.os.family == "RedHat".
+var arcAgentV1FactsFilterRe = regexp.MustCompile(`^\s*facts((?:\.[\w-]+)+)\s*(==|!=)\s*"([^"]*)"\s*$`)
+
+type arcAgentV1FactsFilter struct {
+	path  []string
+	op    string
+	value string
+}
+
+func arcAgentV1ParseFactsFilter(expr string) (*arcAgentV1FactsFilter, error) {
+	m := arcAgentV1FactsFilterRe.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("Invalid %q facts filter, expected the 'facts.<path> == \"<value>\"' or 'facts.<path> != \"<value>\"' format", expr)
+	}
+
+	return &arcAgentV1FactsFilter{
+		path:  strings.Split(strings.TrimPrefix(m[1], "."), "."),
+		op:    m[2],
+		value: m[3],
+	}, nil
+}
+
+// serverFilter returns the Arc query, which can be evaluated by the API. Only
+// the top level facts can be filtered on the server side.
+func (f *arcAgentV1FactsFilter) serverFilter() string {
+	if len(f.path) != 1 || f.op != "==" {
+		return ""
+	}
+
+	return fmt.Sprintf("@%s = %q", f.path[0], f.value)
+}
+
+func (f *arcAgentV1FactsFilter) match(facts map[string]interface{}) bool {
+	var v interface{} = facts
+	for _, key := range f.path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return f.op == "!="
+		}
+		if v, ok = m[key]; !ok {
+			return f.op == "!="
+		}
+	}
+
+	return (fmt.Sprint(v) == f.value) == (f.op == "==")
+}
+
+func validateArcAgentV1FactsFilter(v interface{}, k string) ([]string, []error) {
+	if _, err := arcAgentV1ParseFactsFilter(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q: %s", k, err)}
+	}
+
+	return nil, nil
+}
+
+// arcAgentV1GetFacts retrieves the facts of the agents, which are missing in
+// the agents list. The limit bounds the amount of the concurrent requests.
+func arcAgentV1GetFacts(arcClient *gophercloud.ServiceClient, allAgents []agents.Agent, limit int) error {
+	var ids []string
+	for _, agent := range allAgents {
+		if len(agent.Facts) == 0 {
+			ids = append(ids, agent.AgentID)
+		}
+	}
+
+	var mutex sync.Mutex
+	facts := make(map[string]map[string]interface{}, len(ids))
+	errors := forEachConcurrently(ids, limit, func(id string) error {
+		v, err := agents.GetFacts(arcClient, id).Extract()
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		facts[id] = v
+		mutex.Unlock()
+		return nil
+	})
+	for _, id := range ids {
+		if err := errors[id]; err != nil {
+			return fmt.Errorf("Unable to retrieve facts for %s agent: %s", id, err)
+		}
+	}
+
+	for i, agent := range allAgents {
+		if len(agent.Facts) == 0 {
+			allAgents[i].Facts = facts[agent.AgentID]
+		}
+	}
+
+	return nil
+}
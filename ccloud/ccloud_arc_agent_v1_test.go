@@ -0,0 +1,194 @@
+package ccloud
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/sapcc/gophercloud-sapcc/arc/v1/agents"
+)
+
+func testServiceClient(server *httptest.Server) *gophercloud.ServiceClient {
+	return &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{HTTPClient: *server.Client()},
+		Endpoint:       server.URL + "/",
+	}
+}
+
+func TestUpdateArcAgentTagsV1DefaultTags(t *testing.T) {
+	var deleted []string
+	var created map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+		case r.Method == "POST" && r.URL.Path == "/agents/agent/tags":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("failed to decode the tags: %s", err)
+			}
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tags := map[string]interface{}{"env": "dev"}
+
+	// the resource tag takes precedence over the default tag
+	oldTags := mergeDefaultTags(map[string]string{"env": "prod", "team": "a"}, tags)
+	if expected := map[string]interface{}{"env": "dev", "team": "a"}; !reflect.DeepEqual(oldTags, expected) {
+		t.Fatalf("expected %v, got %v", expected, oldTags)
+	}
+
+	// the removed default tag is deleted remotely
+	newTags := mergeDefaultTags(map[string]string{"env": "prod"}, tags)
+	if err := updateArcAgentTagsV1(testServiceClient(server), "agent", oldTags, newTags); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sort.Strings(deleted)
+	if expected := []string{"/agents/agent/tags/team"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("expected %v deleted, got %v", expected, deleted)
+	}
+	if expected := map[string]string{"env": "dev"}; !reflect.DeepEqual(created, expected) {
+		t.Errorf("expected %v created, got %v", expected, created)
+	}
+}
+
+func TestArcAgentV1ParseFactsFilter(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected *arcAgentV1FactsFilter
+		server   string
+	}{
+		{`facts.os == "linux"`, &arcAgentV1FactsFilter{path: []string{"os"}, op: "==", value: "linux"}, `@os = "linux"`},
+		{` facts.os.family=="RedHat" `, &arcAgentV1FactsFilter{path: []string{"os", "family"}, op: "==", value: "RedHat"}, ""},
+		{`facts.agent-version != "1.0"`, &arcAgentV1FactsFilter{path: []string{"agent-version"}, op: "!=", value: "1.0"}, ""},
+		{`facts.os == ""`, &arcAgentV1FactsFilter{path: []string{"os"}, op: "==", value: ""}, `@os = ""`},
+		{`os == "linux"`, nil, ""},
+		{`facts.os = "linux"`, nil, ""},
+		{`facts.os == linux`, nil, ""},
+		{`facts == "linux"`, nil, ""},
+		{`facts.os.family == "RedHat" && facts.os == "linux"`, nil, ""},
+	}
+
+	for _, c := range cases {
+		f, err := arcAgentV1ParseFactsFilter(c.expr)
+		if c.expected == nil {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %+v", c.expr, f)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(f, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.expr, c.expected, f)
+		}
+		if v := f.serverFilter(); v != c.server {
+			t.Errorf("%s: expected %q server filter, got %q", c.expr, c.server, v)
+		}
+	}
+}
+
+func TestArcAgentV1FactsFilterMatch(t *testing.T) {
+	facts := map[string]interface{}{
+		"os":     "linux",
+		"online": true,
+		"memory": map[string]interface{}{"total": float64(2048)},
+		"platform": map[string]interface{}{
+			"family": "RedHat",
+		},
+	}
+
+	cases := []struct {
+		expr     string
+		expected bool
+	}{
+		{`facts.os == "linux"`, true},
+		{`facts.os == "windows"`, false},
+		{`facts.os != "windows"`, true},
+		{`facts.os != "linux"`, false},
+		{`facts.platform.family == "RedHat"`, true},
+		{`facts.platform.family == "Debian"`, false},
+		{`facts.online == "true"`, true},
+		{`facts.memory.total == "2048"`, true},
+		{`facts.missing == "value"`, false},
+		{`facts.missing != "value"`, true},
+		{`facts.os.family == "RedHat"`, false},
+		{`facts.os.family != "RedHat"`, true},
+	}
+
+	for _, c := range cases {
+		f, err := arcAgentV1ParseFactsFilter(c.expr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.expr, err)
+		}
+		if v := f.match(facts); v != c.expected {
+			t.Errorf("%s: expected %t, got %t", c.expr, c.expected, v)
+		}
+	}
+}
+
+func TestArcAgentV1GetFacts(t *testing.T) {
+	var mutex sync.Mutex
+	var requests, inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests++
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		defer func() {
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/agents/"), "/facts")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"hostname": id})
+	}))
+	defer server.Close()
+
+	allAgents := []agents.Agent{
+		{AgentID: "a1"},
+		{AgentID: "a2", Facts: map[string]interface{}{"hostname": "cached"}},
+		{AgentID: "a3"},
+		{AgentID: "a4"},
+		{AgentID: "a5"},
+	}
+	if err := arcAgentV1GetFacts(testServiceClient(server), allAgents, 2); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, agent := range allAgents {
+		expected := agent.AgentID
+		if expected == "a2" {
+			expected = "cached"
+		}
+		if v := agent.Facts["hostname"]; v != expected {
+			t.Errorf("%s: expected the %q hostname, got %v", agent.AgentID, expected, v)
+		}
+	}
+	// the facts of the listed agents are not requested again
+	if requests != 4 {
+		t.Errorf("expected 4 requests, got %d", requests)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxInFlight)
+	}
+}
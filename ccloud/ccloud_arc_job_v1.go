@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 
 	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/pagination"
 	"github.com/sapcc/gophercloud-sapcc/arc/v1/jobs"
 )
 
@@ -231,49 +232,58 @@ func arcCCloudArcJobV1FlattenChef(job *jobs.Job) ([]map[string]interface{}, erro
 	}}, nil
 }
 
-func arcCCloudArcJobV1Filter(d *schema.ResourceData, arcClient *gophercloud.ServiceClient, resourceName string) ([]jobs.Job, error) {
+// arcCCloudArcJobV1Filter lists the Arc jobs, which match the data source
+// filters. The listing stops, when the limit of the matched jobs is reached.
+// Zero limit means no limit.
+func arcCCloudArcJobV1Filter(d *schema.ResourceData, arcClient *gophercloud.ServiceClient, resourceName string, pageSize, limit int) ([]jobs.Job, error) {
 	agentID := d.Get("agent_id").(string)
 	timeout := d.Get("timeout").(int)
 	agent := d.Get("agent").(string)
 	action := d.Get("action").(string)
 	status := d.Get("status").(string)
 
-	listOpts := jobs.ListOpts{AgentID: agentID}
+	listOpts := jobs.ListOpts{AgentID: agentID, PerPage: pageSize}
 
 	log.Printf("[DEBUG] %s list options: %#v", resourceName, listOpts)
 
-	allPages, err := jobs.List(arcClient, listOpts).AllPages()
-	if err != nil {
-		return nil, fmt.Errorf("Unable to list %s: %s", resourceName, err)
-	}
+	var allJobs []jobs.Job
+	err := listPages(jobs.List(arcClient, listOpts), limit, func(page pagination.Page) (int, error) {
+		v, err := jobs.ExtractJobs(page)
+		if err != nil {
+			return 0, fmt.Errorf("Unable to retrieve %s: %s", resourceName, err)
+		}
+		var n int
+		for _, job := range v {
+			found := true
+			if found && timeout > 0 && job.Timeout != timeout {
+				found = false
+			}
+			if found && len(agent) > 0 && job.Agent != agent {
+				found = false
+			}
+			if found && len(action) > 0 && job.Action != action {
+				found = false
+			}
+			if found && len(status) > 0 && job.Status != status {
+				found = false
+			}
 
-	allJobs, err := jobs.ExtractJobs(allPages)
+			if found {
+				allJobs = append(allJobs, job)
+				n++
+			}
+		}
+		return n, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("Unable to retrieve %s: %s", resourceName, err)
+		return nil, fmt.Errorf("Unable to list %s: %s", resourceName, err)
 	}
 
-	var jobs []jobs.Job
-	for _, job := range allJobs {
-		found := true
-		if found && timeout > 0 && job.Timeout != timeout {
-			found = false
-		}
-		if found && len(agent) > 0 && job.Agent != agent {
-			found = false
-		}
-		if found && len(action) > 0 && job.Action != action {
-			found = false
-		}
-		if found && len(status) > 0 && job.Status != status {
-			found = false
-		}
-
-		if found {
-			jobs = append(jobs, job)
-		}
+	if limit > 0 && len(allJobs) > limit {
+		allJobs = allJobs[:limit]
 	}
 
-	return jobs, nil
+	return allJobs, nil
 }
 
 func flattenArcJobUserV1(user jobs.User) []interface{} {
@@ -0,0 +1,65 @@
+package ccloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestArcCCloudArcJobV1Filter(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if v := r.URL.Query().Get("per_page"); v != "2" {
+			t.Errorf("expected the 2 page size, got %q", v)
+		}
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Pagination-Pages", "3")
+		fmt.Fprintf(w, `[{"request_id":"%[1]s-1","status":"complete"},{"request_id":"%[1]s-2","status":"failed"}]`, page)
+	}))
+	defer server.Close()
+
+	client := testServiceClient(server)
+
+	cases := []struct {
+		name     string
+		status   string
+		limit    int
+		expected []string
+		requests int
+	}{
+		{"all jobs", "", 0, []string{"1-1", "1-2", "2-1", "2-2", "3-1", "3-2"}, 3},
+		{"limit", "", 3, []string{"1-1", "1-2", "2-1"}, 2},
+		{"filtered limit", "complete", 2, []string{"1-1", "2-1"}, 2},
+	}
+
+	for _, c := range cases {
+		requests = 0
+		d := schema.TestResourceDataRaw(t, dataSourceCCloudArcJobIDsV1().Schema, map[string]interface{}{
+			"status": c.status,
+		})
+		jobs, err := arcCCloudArcJobV1Filter(d, client, "ccloud_arc_job_ids_v1", 2, c.limit)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+
+		var ids []string
+		for _, job := range jobs {
+			ids = append(ids, job.RequestID)
+		}
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("%s: expected %v jobs, got %v", c.name, c.expected, ids)
+		}
+		if requests != c.requests {
+			t.Errorf("%s: expected %d requests, got %d", c.name, c.requests, requests)
+		}
+	}
+}
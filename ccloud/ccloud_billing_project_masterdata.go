@@ -1,11 +1,24 @@
 package ccloud
 
 import (
+	"encoding/json"
+	"log"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/sapcc/gophercloud-sapcc/billing/masterdata/projects"
 )
 
 func billingProjectFlattenCostObject(co projects.CostObject) []map[string]interface{} {
+	if co.Inherited {
+		// name and type are defined by the parent, they conflict with the
+		// inherited flag and must not appear in the state
+		return []map[string]interface{}{{
+			"inherited": co.Inherited,
+			"name":      "",
+			"type":      "",
+		}}
+	}
+
 	return []map[string]interface{}{{
 		"inherited": co.Inherited,
 		"name":      co.Name,
@@ -50,3 +63,15 @@ func replaceEmpty(d *schema.ResourceData, field string, b string) string {
 	}
 	return v.(string)
 }
+
+// billingProjectMasterdataJSON returns the full project masterdata as a JSON
+// document, e.g. to be consumed by the external tools.
+func billingProjectMasterdataJSON(project *projects.Project) string {
+	v, err := json.Marshal(project)
+	if err != nil {
+		log.Printf("[DEBUG] Failed to marshal the %s project masterdata: %s", project.ProjectID, err)
+		return ""
+	}
+
+	return string(v)
+}
@@ -0,0 +1,83 @@
+package ccloud
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sapcc/gophercloud-sapcc/billing/masterdata/projects"
+)
+
+func TestBillingProjectCostObjectInheritedToggle(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   map[string]interface{}
+		backend  projects.CostObject
+		expected projects.CostObject
+	}{
+		{
+			name:     "explicit cost object",
+			config:   map[string]interface{}{"inherited": false, "name": "co1", "type": "IO"},
+			backend:  projects.CostObject{Name: "co1", Type: "IO"},
+			expected: projects.CostObject{Name: "co1", Type: "IO"},
+		},
+		{
+			// the backend may still report the previous name and type
+			name:     "false to true",
+			config:   map[string]interface{}{"inherited": true, "name": "co1", "type": "IO"},
+			backend:  projects.CostObject{Inherited: true, Name: "co1", Type: "IO"},
+			expected: projects.CostObject{Inherited: true},
+		},
+		{
+			name:     "true to false",
+			config:   map[string]interface{}{"inherited": false, "name": "co2", "type": "CC"},
+			backend:  projects.CostObject{Name: "co2", Type: "CC"},
+			expected: projects.CostObject{Name: "co2", Type: "CC"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if v := billingProjectExpandCostObject([]interface{}{c.config}); !reflect.DeepEqual(v, c.expected) {
+				t.Errorf("expected %+v request, got %+v", c.expected, v)
+			}
+
+			// the state matches the expanded config, i.e. there is no diff
+			state := billingProjectFlattenCostObject(c.backend)
+			if v := billingProjectExpandCostObject([]interface{}{state[0]}); !reflect.DeepEqual(v, c.expected) {
+				t.Errorf("expected %+v state, got %+v", c.expected, v)
+			}
+			if c.expected.Inherited && (state[0]["name"] != "" || state[0]["type"] != "") {
+				t.Errorf("expected blank name and type, got %v", state[0])
+			}
+		})
+	}
+}
+
+func TestBillingProjectMasterdataJSON(t *testing.T) {
+	project := &projects.Project{
+		ProjectID:                      "p1",
+		ResponsiblePrimaryContactID:    "D000000",
+		ResponsiblePrimaryContactEmail: "mail@example.com",
+		CostObject:                     projects.CostObject{Name: "co1", Type: "IO"},
+		Collector:                      "collector1",
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(billingProjectMasterdataJSON(project)), &v); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"project_id":                        "p1",
+		"responsible_primary_contact_id":    "D000000",
+		"responsible_primary_contact_email": "mail@example.com",
+		"cost_object":                       map[string]interface{}{"inherited": false, "name": "co1", "type": "IO"},
+		"collector":                         "collector1",
+	}
+	for k, e := range expected {
+		if !reflect.DeepEqual(v[k], e) {
+			t.Errorf("expected %v %s, got %v", e, k, v[k])
+		}
+	}
+}
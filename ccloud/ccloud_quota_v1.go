@@ -1,14 +1,25 @@
 package ccloud
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 
 	"github.com/gophercloud/gophercloud"
+	identityProjects "github.com/gophercloud/gophercloud/openstack/identity/v3/projects"
+	"github.com/sapcc/gophercloud-sapcc/resources/v1/domains"
 	"github.com/sapcc/gophercloud-sapcc/resources/v1/projects"
 	"github.com/sapcc/limes"
 )
@@ -64,11 +75,935 @@ var (
 	}
 )
 
+// limesDynamicServices contains services, which may expose additional
+// region specific resources. These resources are discovered from the Limes
+// report and can be set using the quota JSON only.
+// The quota values are stored as the Terraform integers, i.e. the Go int,
+// which must keep the byte values, e.g. the object-store capacity, exact. The
+// build fails on the 32-bit platforms, which are not supported.
+const _ int = math.MaxInt64
+
+var limesDynamicServices = map[string]bool{
+	"object-store": true,
+}
+
+// limesServiceAliases contains alternative service types, which may be exposed
+// by Limes instead of the default one, depending on the region.
+var limesServiceAliases = map[string][]string{
+	"volumev2": {"volumev3", "volume"},
+}
+
+// limesServiceType returns the service type, which is actually exposed by the
+// Limes report. The exists function reports whether the report contains the
+// service.
+func limesServiceType(service string, exists func(string) bool) string {
+	if exists(service) {
+		return service
+	}
+
+	for _, alias := range limesServiceAliases[service] {
+		if exists(alias) {
+			log.Printf("[WARN] Limes service %q is not available, using %q instead", service, alias)
+			return alias
+		}
+	}
+
+	return service
+}
+
+// limesAliasQuotaRequest renames the request services to the service types
+// exposed by the Limes report.
+func limesAliasQuotaRequest(services limes.QuotaRequest, exists func(string) bool) limes.QuotaRequest {
+	res := make(limes.QuotaRequest, len(services))
+	for service, quota := range services {
+		res[limesServiceType(service, exists)] = quota
+	}
+	return res
+}
+
+// limesQuotaRequestHasAliases reports whether the request contains services,
+// which may be exposed under an alternative service type.
+func limesQuotaRequestHasAliases(services limes.QuotaRequest) bool {
+	for service := range services {
+		if _, ok := limesServiceAliases[service]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// limesResourceKey returns the "service/resource" key, which is used in the
+// computed per resource attributes.
+func limesResourceKey(service, resource string) string {
+	return fmt.Sprintf("%s/%s", sanitize(service), resource)
+}
+
+// limesManagedResources returns the set of the "service/resource" keys, which
+// are managed by the resource. It returns nil, when all resources are managed.
+func limesManagedResources(d *schema.ResourceData) map[string]bool {
+	v := d.Get("managed_resources").(*schema.Set)
+	if v.Len() == 0 {
+		return nil
+	}
+
+	return limesResourceKeys(v)
+}
+
+// limesResourceKeys returns the set of the normalized "service/resource" keys.
+func limesResourceKeys(v *schema.Set) map[string]bool {
+	res := make(map[string]bool, v.Len())
+	for _, key := range v.List() {
+		// both "object-store/capacity" and "objectstore/capacity" are allowed
+		parts := strings.SplitN(key.(string), "/", 2)
+		res[limesResourceKey(parts[0], parts[len(parts)-1])] = true
+	}
+
+	return res
+}
+
+// limesIgnoreUsageFor returns the set of the "service/resource" keys, which
+// are excluded from the usage dependent checks. The keys are added for the
+// service aliases as well.
+func limesIgnoreUsageFor(d *schema.ResourceData) map[string]bool {
+	res := limesResourceKeys(d.Get("ignore_usage_for").(*schema.Set))
+	for service, aliases := range limesServiceAliases {
+		for resource := range limesServices[service] {
+			if !res[limesResourceKey(service, resource)] {
+				continue
+			}
+			for _, alias := range aliases {
+				res[limesResourceKey(alias, resource)] = true
+			}
+		}
+	}
+
+	return res
+}
+
+// limesManagedServices returns the set of the Limes service names, which are
+// listed in the managed_services, or nil, when the argument is not set.
+func limesManagedServices(d *schema.ResourceData) map[string]bool {
+	v := d.Get("managed_services").(*schema.Set)
+	if v.Len() == 0 {
+		return nil
+	}
+
+	res := make(map[string]bool, v.Len())
+	for _, name := range v.List() {
+		if service, ok := limesServiceByName(name.(string)); ok {
+			res[service] = true
+		}
+	}
+
+	return res
+}
+
+// limesServiceManaged reports whether the service is tracked in its service
+// block. The explicit managed_services list takes precedence over the
+// managed_resources. Without both lists the resource falls back to the
+// services, which are already in the state.
+func limesServiceManaged(d *schema.ResourceData, service string, managedServices, whitelist map[string]bool) bool {
+	if managedServices != nil {
+		return managedServices[service]
+	}
+	if whitelist != nil {
+		return limesServiceWhitelisted(service, whitelist)
+	}
+
+	return len(d.Get(sanitize(service)).([]interface{})) > 0
+}
+
+// limesServiceWhitelisted reports whether the whitelist contains any
+// resource of the service.
+func limesServiceWhitelisted(service string, whitelist map[string]bool) bool {
+	for resource := range limesServices[service] {
+		if whitelist[limesResourceKey(service, resource)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateLimesResourceKey(v interface{}, k string) ([]string, []error) {
+	parts := strings.SplitN(v.(string), "/", 2)
+	if len(parts) == 2 {
+		if service, ok := limesServiceByName(parts[0]); ok {
+			if _, ok := limesServices[service][parts[1]]; ok || limesDynamicServices[service] {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, []error{fmt.Errorf("%q: unknown %q resource, expected the \"service/resource\" format, e.g. \"compute/cores\"", k, v)}
+}
+
+// limesClampQuotaRequest reduces the requested project quota to the maximum,
+// which can be backed by the domain quota.
+func limesClampQuotaRequest(services limes.QuotaRequest, project *limes.ProjectReport, domain *limes.DomainReport) {
+	for service, quota := range services {
+		if project.Services[service] == nil || domain.Services[service] == nil {
+			continue
+		}
+		for resource, v := range quota.Resources {
+			dr := domain.Services[service].Resources[resource]
+			max, ok := limesMaxProjectQuota(project.Services[service].Resources[resource], dr)
+			if !ok {
+				continue
+			}
+
+			// compare in the base units, since the requested unit may
+			// differ from the reported one, e.g. GiB and MiB
+			base, reqMultiple := v.Unit.Base()
+			maxBase, maxMultiple := dr.Unit.Base()
+			if base != maxBase {
+				log.Printf("[DEBUG] Cannot clamp %s quota: incompatible %q and %q units", limesResourceKey(service, resource), v.Unit, dr.Unit)
+				continue
+			}
+
+			if v.Value*reqMultiple > max*maxMultiple {
+				clamped := limes.ValueWithUnit{Value: max * maxMultiple / reqMultiple, Unit: v.Unit}
+				log.Printf("[WARN] Clamping %s quota to the domain maximum: %s -> %s", limesResourceKey(service, resource), v.String(), clamped.String())
+				quota.Resources[resource] = clamped
+			}
+		}
+	}
+}
+
+// limesDomainHeadroom returns the domain quota, which is not yet assigned to
+// the domain projects, keyed by "service/resource" in the reported units.
+func limesDomainHeadroom(domain *limes.DomainReport) map[string]int64 {
+	res := make(map[string]int64)
+	exists := func(s string) bool { _, ok := domain.Services[s]; return ok }
+	for service, resources := range limesServices {
+		srv := domain.Services[limesServiceType(service, exists)]
+		if srv == nil {
+			continue
+		}
+		for resource := range resources {
+			r := srv.Resources[resource]
+			if r == nil || r.DomainQuota == nil || r.ProjectsQuota == nil {
+				continue
+			}
+			res[limesResourceKey(service, resource)] = int64(*r.DomainQuota) - int64(*r.ProjectsQuota)
+		}
+	}
+
+	return res
+}
+
+// limesQuotaIncreased reports whether the quota request contains any increase.
+func limesQuotaIncreased(before, after limes.QuotaRequest) bool {
+	for service, srv := range after {
+		for resource, v := range srv.Resources {
+			if old, ok := before[service].Resources[resource]; ok && v.Value > old.Value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// limesPlanDomainHeadroom subtracts the planned project quota increases from
+// the domain headroom.
+func limesPlanDomainHeadroom(headroom map[string]int64, domain *limes.DomainReport, before, after limes.QuotaRequest) {
+	exists := func(s string) bool { _, ok := domain.Services[s]; return ok }
+	for service, srv := range after {
+		dsrv := domain.Services[limesServiceType(service, exists)]
+		for resource, v := range srv.Resources {
+			old, ok := before[service].Resources[resource]
+			if !ok || v.Value <= old.Value {
+				continue
+			}
+
+			key := limesResourceKey(service, resource)
+			if dsrv == nil || dsrv.Resources[resource] == nil {
+				continue
+			}
+			if _, ok := headroom[key]; !ok {
+				continue
+			}
+			delta, err := limes.ValueWithUnit{Value: v.Value - old.Value, Unit: v.Unit}.ConvertTo(dsrv.Resources[resource].Unit)
+			if err != nil {
+				log.Printf("[DEBUG] Failed to convert %s quota increase: %s", key, err)
+				continue
+			}
+			headroom[key] -= int64(delta.Value)
+		}
+	}
+}
+
+// limesDiffQuotaRequests returns the project quota before and after the
+// planned change, built from the changed quota arguments.
+func limesDiffQuotaRequests(d *schema.ResourceDiff) (limes.QuotaRequest, limes.QuotaRequest) {
+	before, after := limes.QuotaRequest{}, limes.QuotaRequest{}
+	merge := func(dst, src limes.QuotaRequest) {
+		for service, srv := range src {
+			if _, ok := dst[service]; !ok {
+				dst[service] = limes.ServiceQuotaRequest{Resources: make(limes.ResourceQuotaRequest)}
+			}
+			for resource, v := range srv.Resources {
+				dst[service].Resources[resource] = v
+			}
+		}
+	}
+
+	if d.HasChange("quota_json") {
+		o, n := d.GetChange("quota_json")
+		if v, err := expandLimesQuotaJSON(o.(string)); err == nil {
+			merge(before, v)
+		}
+		if v, err := expandLimesQuotaJSON(n.(string)); err == nil {
+			merge(after, v)
+		}
+	}
+
+	if d.HasChange("quota") {
+		o, n := d.GetChange("quota")
+		if v, err := expandLimesQuotaMap(o.(map[string]interface{})); err == nil {
+			merge(before, v)
+		}
+		if v, err := expandLimesQuotaMap(n.(map[string]interface{})); err == nil {
+			merge(after, v)
+		}
+	}
+
+	for service, resources := range limesServices {
+		for resource, unit := range resources {
+			key := fmt.Sprintf("%s.0.%s", sanitize(service), resource)
+			if !d.HasChange(key) {
+				continue
+			}
+			o, n := d.GetChange(key)
+			merge(before, limes.QuotaRequest{service: {Resources: limes.ResourceQuotaRequest{
+				resource: {Value: uint64(o.(int)), Unit: unit},
+			}}})
+			merge(after, limes.QuotaRequest{service: {Resources: limes.ResourceQuotaRequest{
+				resource: {Value: uint64(n.(int)), Unit: unit},
+			}}})
+		}
+	}
+
+	return before, after
+}
+
+// limesMaxProjectQuota returns the maximum project resource quota, which can
+// be provided by the domain, i.e. the current project quota and the domain
+// quota, which is not yet assigned to the domain projects. The value is in
+// the reported resource unit.
+func limesMaxProjectQuota(pr *limes.ProjectResourceReport, dr *limes.DomainResourceReport) (uint64, bool) {
+	if pr == nil || dr == nil || dr.DomainQuota == nil || dr.ProjectsQuota == nil {
+		return 0, false
+	}
+
+	var max uint64
+	if pr.Quota != nil {
+		max = *pr.Quota
+	}
+	if *dr.DomainQuota > *dr.ProjectsQuota {
+		max += *dr.DomainQuota - *dr.ProjectsQuota
+	}
+
+	return max, true
+}
+
+// limesQuotaBelowUsage reports whether the requested quota is below the
+// current resource usage. The values are compared in the base units, since
+// the requested unit may differ from the reported one.
+func limesQuotaBelowUsage(v limes.ValueWithUnit, r *limes.ProjectResourceReport) bool {
+	base, multiple := v.Unit.Base()
+	usageBase, usageMultiple := r.Unit.Base()
+	if base != usageBase {
+		return false
+	}
+
+	return v.Value*multiple < r.Usage*usageMultiple
+}
+
+// limesCheckQuotaBelowUsage returns an error, when a requested quota is below
+// the current resource usage. The resources listed in the ignoreUsage are not
+// checked.
+func limesCheckQuotaBelowUsage(services limes.QuotaRequest, project *limes.ProjectReport, ignoreUsage map[string]bool) error {
+	for service, srv := range services {
+		for resource, v := range srv.Resources {
+			if project.Services[service] == nil || project.Services[service].Resources[resource] == nil {
+				continue
+			}
+			if ignoreUsage[limesResourceKey(service, resource)] {
+				continue
+			}
+			if r := project.Services[service].Resources[resource]; limesQuotaBelowUsage(v, r) {
+				return fmt.Errorf("%s quota %s is below the current usage %s", limesResourceKey(service, resource), v, limes.ValueWithUnit{Value: r.Usage, Unit: r.Unit})
+			}
+		}
+	}
+
+	return nil
+}
+
+// limesQuotaRatios contains the minimal expected ratios between the related
+// resources. A lower ratio usually indicates a unit mismatch, e.g. the ram
+// quota specified in Gibibytes instead of Mebibytes.
+var limesQuotaRatios = []struct {
+	service, resource, perService, perResource string
+	min                                        float64
+}{
+	{"compute", "ram", "compute", "cores", 1024},
+}
+
+// limesCheckQuotaRatios logs a warning, when the resulting project quota
+// doesn't match the expected ratios.
+func limesCheckQuotaRatios(services limes.QuotaRequest, project *limes.ProjectReport) {
+	value := func(service, resource string) (uint64, bool) {
+		if v, ok := services[service].Resources[resource]; ok {
+			return v.Value, true
+		}
+		if srv := project.Services[service]; srv != nil && srv.Resources[resource] != nil && srv.Resources[resource].Quota != nil {
+			return *srv.Resources[resource].Quota, true
+		}
+		return 0, false
+	}
+
+	for _, r := range limesQuotaRatios {
+		_, ok1 := services[r.service].Resources[r.resource]
+		_, ok2 := services[r.perService].Resources[r.perResource]
+		if !ok1 && !ok2 {
+			// nothing changed
+			continue
+		}
+
+		v, ok1 := value(r.service, r.resource)
+		per, ok2 := value(r.perService, r.perResource)
+		if !ok1 || !ok2 || per == 0 {
+			continue
+		}
+
+		if ratio := float64(v) / float64(per); ratio < r.min {
+			log.Printf("[WARN] The %s quota is %.2f %s per %s, expected at least %.0f: check the quota units", limesResourceKey(r.service, r.resource), ratio, limesServices[r.service][r.resource], limesResourceKey(r.perService, r.perResource), r.min)
+		}
+	}
+}
+
+// limesSplitQuotaRequest splits the quota request into the increases and the
+// decreases of the current project quota. Empty requests are omitted. The
+// request is sent at once, when no service has both increases and decreases,
+// since the dependent resources belong to the same service.
+func limesSplitQuotaRequest(services limes.QuotaRequest, project *limes.ProjectReport) []limes.QuotaRequest {
+	increases := make(limes.QuotaRequest)
+	decreases := make(limes.QuotaRequest)
+	add := func(req limes.QuotaRequest, service, resource string, v limes.ValueWithUnit) {
+		if _, ok := req[service]; !ok {
+			req[service] = limes.ServiceQuotaRequest{Resources: make(limes.ResourceQuotaRequest)}
+		}
+		req[service].Resources[resource] = v
+	}
+
+	for service, quota := range services {
+		for resource, v := range quota.Resources {
+			if srv := project.Services[service]; srv != nil && srv.Resources[resource] != nil && srv.Resources[resource].Quota != nil {
+				r := srv.Resources[resource]
+				if c, err := v.ConvertTo(r.Unit); err == nil && c.Value < *r.Quota {
+					add(decreases, service, resource, v)
+					continue
+				}
+			}
+			add(increases, service, resource, v)
+		}
+	}
+
+	split := false
+	for service := range decreases {
+		if _, ok := increases[service]; ok {
+			split = true
+			break
+		}
+	}
+	if !split {
+		for service, quota := range decreases {
+			increases[service] = quota
+		}
+		decreases = nil
+	}
+
+	var res []limes.QuotaRequest
+	for _, req := range []limes.QuotaRequest{increases, decreases} {
+		if len(req) > 0 {
+			res = append(res, req)
+		}
+	}
+
+	return res
+}
+
+// limesDomainQuotaExceededRe matches the Limes rejection of a project quota,
+// which exceeds the domain quota.
+var limesDomainQuotaExceededRe = regexp.MustCompile(`^cannot change (\S+)/(\S+) quota: domain quota exceeded \(maximum acceptable project quota is ([^)]+)\)`)
+
+// limesDomainQuotaExceededError is returned, when Limes rejects the project
+// quota, because the domain quota is exhausted.
+type limesDomainQuotaExceededError struct {
+	Service   string
+	Resource  string
+	Requested limes.ValueWithUnit
+	Maximum   limes.ValueWithUnit
+}
+
+func (e limesDomainQuotaExceededError) Error() string {
+	return fmt.Sprintf("domain quota exhausted for %s: requested %s, maximum acceptable project quota is %s, shortfall is %s",
+		limesResourceKey(e.Service, e.Resource), e.Requested, e.Maximum,
+		limes.ValueWithUnit{Value: e.Requested.Value - e.Maximum.Value, Unit: e.Maximum.Unit})
+}
+
+// limesParseDomainQuotaExceeded returns the typed errors for the domain quota
+// exhaustion lines of the Limes rejection body.
+func limesParseDomainQuotaExceeded(body []byte, services limes.QuotaRequest) []error {
+	var errs []error
+	for _, line := range strings.Split(string(body), "\n") {
+		m := limesDomainQuotaExceededRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+
+		v, ok := services[m[1]].Resources[m[2]]
+		if !ok {
+			continue
+		}
+		max, err := v.Unit.Parse(m[3])
+		if err != nil || max > v.Value {
+			log.Printf("[DEBUG] Failed to parse the %s/%s maximum quota %q: %v", m[1], m[2], m[3], err)
+			continue
+		}
+
+		errs = append(errs, limesDomainQuotaExceededError{
+			Service:   m[1],
+			Resource:  m[2],
+			Requested: v,
+			Maximum:   limes.ValueWithUnit{Value: max, Unit: v.Unit},
+		})
+	}
+
+	return errs
+}
+
+// limesCheckAppliedQuota logs a warning, when the quota reported by Limes
+// differs from the requested one.
+func limesCheckAppliedQuota(services limes.QuotaRequest, project *limes.ProjectReport) {
+	for service, quota := range services {
+		srv := project.Services[service]
+		for resource, v := range quota.Resources {
+			if srv == nil || srv.Resources[resource] == nil || srv.Resources[resource].Quota == nil {
+				continue
+			}
+			r := srv.Resources[resource]
+			applied := limes.ValueWithUnit{Value: *r.Quota, Unit: r.Unit}
+			if requested, err := v.ConvertTo(r.Unit); err == nil && requested.Value != applied.Value {
+				log.Printf("[WARN] The applied %s quota differs from the requested one: %s -> %s", limesResourceKey(service, resource), v.String(), applied.String())
+			}
+		}
+	}
+}
+
+type limesQuotaChange struct {
+	Service  string     `json:"service"`
+	Resource string     `json:"resource"`
+	Unit     limes.Unit `json:"unit"`
+	Old      *uint64    `json:"old"`
+	New      *uint64    `json:"new"`
+}
+
+type limesQuotaNotification struct {
+	DomainID  string             `json:"domain_id"`
+	ProjectID string             `json:"project_id"`
+	Changes   []limesQuotaChange `json:"changes"`
+}
+
+// limesQuotaChanges returns the requested project quota values, which were
+// changed by the update, sorted by the "service/resource" key.
+func limesQuotaChanges(services limes.QuotaRequest, before, after *limes.ProjectReport) []limesQuotaChange {
+	value := func(project *limes.ProjectReport, service, resource string) (*uint64, limes.Unit) {
+		if srv := project.Services[service]; srv != nil && srv.Resources[resource] != nil {
+			return srv.Resources[resource].Quota, srv.Resources[resource].Unit
+		}
+		return nil, limes.UnitNone
+	}
+
+	var changes []limesQuotaChange
+	for service, quota := range services {
+		for resource := range quota.Resources {
+			oldValue, _ := value(before, service, resource)
+			newValue, unit := value(after, service, resource)
+			if oldValue != nil && newValue != nil && *oldValue == *newValue {
+				continue
+			}
+			changes = append(changes, limesQuotaChange{
+				Service:  service,
+				Resource: resource,
+				Unit:     unit,
+				Old:      oldValue,
+				New:      newValue,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return limesResourceKey(changes[i].Service, changes[i].Resource) <
+			limesResourceKey(changes[j].Service, changes[j].Resource)
+	})
+
+	return changes
+}
+
+// limesNotifyClient sends the quota change notifications. The provider HTTP
+// client is not used, since the webhook is not an OpenStack service and must
+// not receive the OpenStack auth headers.
+var limesNotifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// limesNotifyQuotaChange sends the changed project quota values to the
+// webhook URL. Failures are logged only.
+func limesNotifyQuotaChange(url, domainID, projectID string, changes []limesQuotaChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	notification := limesQuotaNotification{
+		DomainID:  domainID,
+		ProjectID: projectID,
+		Changes:   changes,
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		log.Printf("[WARN] Failed to marshal the quota change notification: %s", err)
+		return
+	}
+
+	resp, err := limesNotifyClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[WARN] Failed to send the quota change notification to %s: %s", url, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("[WARN] Failed to send the quota change notification to %s: unexpected %d response code", url, resp.StatusCode)
+	}
+}
+
+// limesEmitQuotaMetrics sends the summary of the project quota update to the
+// statsd endpoint, i.e. the amount of the changed resources and the sum of
+// the quota differences. Failures are logged only.
+func limesEmitQuotaMetrics(endpoint string, changes []limesQuotaChange) {
+	var delta int64
+	for _, c := range changes {
+		if c.New != nil {
+			delta += int64(*c.New)
+		}
+		if c.Old != nil {
+			delta -= int64(*c.Old)
+		}
+	}
+
+	conn, err := net.Dial("udp", endpoint)
+	if err != nil {
+		log.Printf("[WARN] Failed to send the quota metrics to %s: %s", endpoint, err)
+		return
+	}
+	defer conn.Close()
+
+	metrics := fmt.Sprintf("ccloud.project_quota.resources_changed:%d|g\nccloud.project_quota.total_delta:%d|g", len(changes), delta)
+	if _, err := conn.Write([]byte(metrics)); err != nil {
+		log.Printf("[WARN] Failed to send the quota metrics to %s: %s", endpoint, err)
+	}
+}
+
+// expandLimesQuotaJSON parses the JSON document, which contains the quota
+// values keyed by the service and the resource names, e.g.
+// {"compute":{"cores":10},"object-store":{"capacity":1073741824}}. The values
+// use the limesServices units, i.e. the units of the service blocks.
+func expandLimesQuotaJSON(raw string) (limes.QuotaRequest, error) {
+	var v map[string]map[string]uint64
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return nil, fmt.Errorf("Failed to parse the quota JSON: %s", err)
+	}
+
+	services := make(limes.QuotaRequest, len(v))
+	for k, resources := range v {
+		service, ok := limesServiceByName(k)
+		if !ok {
+			return nil, fmt.Errorf("Unknown %q service in the quota JSON", k)
+		}
+
+		quota := limes.ServiceQuotaRequest{Resources: make(limes.ResourceQuotaRequest, len(resources))}
+		for resource, value := range resources {
+			unit, ok := limesServices[service][resource]
+			if !ok && !limesDynamicServices[service] {
+				return nil, fmt.Errorf("Unknown %q resource of the %q service in the quota JSON", resource, k)
+			}
+			// the unit of the dynamic resource is resolved from the Limes report
+			quota.Resources[resource] = limes.ValueWithUnit{Value: value, Unit: unit}
+		}
+		services[service] = quota
+	}
+
+	return services, nil
+}
+
+// limesResolveDynamicResources sets the units of the requested dynamic
+// resources, which are not known in advance, from the Limes report.
+func limesResolveDynamicResources(services limes.QuotaRequest, project *limes.ProjectReport) error {
+	for service := range limesDynamicServices {
+		for resource, v := range services[service].Resources {
+			if _, ok := limesServices[service][resource]; ok {
+				continue
+			}
+			srv := project.Services[service]
+			if srv == nil || srv.Resources[resource] == nil {
+				return fmt.Errorf("Unknown %q resource of the %q service", resource, service)
+			}
+			v.Unit = srv.Resources[resource].Unit
+			services[service].Resources[resource] = v
+		}
+	}
+
+	return nil
+}
+
+// expandLimesQuotaMap converts the map with the quota values, keyed by
+// "service/resource", e.g. {"compute/cores":10}, into the quota request.
+func expandLimesQuotaMap(v map[string]interface{}) (limes.QuotaRequest, error) {
+	services := make(limes.QuotaRequest)
+	for key, value := range v {
+		parts := strings.SplitN(key, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid %q quota key, expected the \"service/resource\" format", key)
+		}
+
+		service, ok := limesServiceByName(parts[0])
+		if !ok {
+			return nil, fmt.Errorf("Unknown %q service in the quota map", parts[0])
+		}
+		resource := parts[1]
+		unit, ok := limesServices[service][resource]
+		if !ok && !limesDynamicServices[service] {
+			return nil, fmt.Errorf("Unknown %q resource of the %q service in the quota map", resource, parts[0])
+		}
+
+		if _, ok := services[service]; !ok {
+			services[service] = limes.ServiceQuotaRequest{Resources: make(limes.ResourceQuotaRequest)}
+		}
+		// the raw config values may be not yet converted during the validation,
+		// the integers are parsed without the float conversion to keep the
+		// large values, e.g. the object-store capacity in bytes, exact
+		var i int64
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("Invalid %q quota value: must be an integer", key)
+			}
+			i = int64(v)
+		case int:
+			i = int64(v)
+		case string:
+			var err error
+			if i, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, fmt.Errorf("Invalid %q quota value: %s", key, err)
+			}
+		default:
+			return nil, fmt.Errorf("Invalid %q quota value: unexpected %T type", key, value)
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("Invalid %q quota value: must not be negative", key)
+		}
+		services[service].Resources[resource] = limes.ValueWithUnit{Value: uint64(i), Unit: unit}
+	}
+
+	return services, nil
+}
+
+// unknownVariableValue is the value of the config attributes, which are not
+// yet known during the validation. It mirrors the hcl2shim.UnknownVariableValue,
+// which is internal to the SDK.
+const unknownVariableValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func validateLimesQuotaMap(v interface{}, k string) ([]string, []error) {
+	known := make(map[string]interface{})
+	for key, value := range v.(map[string]interface{}) {
+		// the unknown values are validated, when they become known
+		if value == unknownVariableValue {
+			continue
+		}
+		known[key] = value
+	}
+
+	if _, err := expandLimesQuotaMap(known); err != nil {
+		return nil, []error{fmt.Errorf("%q: %s", k, err)}
+	}
+
+	return nil, nil
+}
+
+// limesServiceByName returns the Limes service name, which corresponds to the
+// Limes or the sanitized schema service name.
+func limesServiceByName(name string) (string, bool) {
+	for service := range limesServices {
+		if name == service || name == sanitize(service) {
+			return service, true
+		}
+	}
+	return "", false
+}
+
+// limesKnownServiceType reports whether the Limes report service type
+// corresponds to one of the supported services or their aliases.
+func limesKnownServiceType(serviceType string) bool {
+	if _, ok := limesServices[serviceType]; ok {
+		return true
+	}
+	for _, aliases := range limesServiceAliases {
+		for _, alias := range aliases {
+			if serviceType == alias {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validateLimesService(v interface{}, k string) ([]string, []error) {
+	if _, ok := limesServiceByName(v.(string)); !ok {
+		return nil, []error{fmt.Errorf("%q: unknown %q service", k, v)}
+	}
+
+	return nil, nil
+}
+
+func validateLimesQuotaJSON(v interface{}, k string) ([]string, []error) {
+	if _, err := expandLimesQuotaJSON(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q: %s", k, err)}
+	}
+
+	return nil, nil
+}
+
+// limesUsagePercent returns the resource usage in percent of the quota. It
+// returns false, when the quota is not tracked or is zero.
+func limesUsagePercent(r *limes.ProjectResourceReport) (float64, bool) {
+	if r.Quota == nil || *r.Quota == 0 {
+		return 0, false
+	}
+
+	return float64(r.Usage) / float64(*r.Quota) * 100, true
+}
+
+// limesEffectiveQuota returns the project resource quota, which can be
+// actually used, i.e. including the quota bursting, in the reported unit.
+func limesEffectiveQuota(project *limes.ProjectReport, r *limes.ProjectResourceReport) *uint64 {
+	if r.UsableQuota != nil || r.Quota == nil {
+		return r.UsableQuota
+	}
+
+	v := *r.Quota
+	if project.Bursting != nil && project.Bursting.Enabled {
+		v = project.Bursting.Multiplier.ApplyTo(v)
+	}
+
+	return &v
+}
+
+// limesDomainUtilizationPercent returns the summed usage of the domain
+// projects in percent of the domain quota. It returns false, when the domain
+// quota is not tracked or is zero.
+func limesDomainUtilizationPercent(r *limes.DomainResourceReport) (float64, bool) {
+	if r.DomainQuota == nil || *r.DomainQuota == 0 {
+		return 0, false
+	}
+
+	return float64(r.Usage) / float64(*r.DomainQuota) * 100, true
+}
+
+// limesFlattenDomainProjects returns the quota of each domain project, keyed
+// by "service/resource" and sorted by the project ID.
+func limesFlattenDomainProjects(reports []limes.ProjectReport) []map[string]interface{} {
+	sort.Slice(reports, func(i, j int) bool { return reports[i].UUID < reports[j].UUID })
+
+	res := make([]map[string]interface{}, 0, len(reports))
+	for _, project := range reports {
+		exists := func(s string) bool { _, ok := project.Services[s]; return ok }
+		quota := make(map[string]int64)
+		for service, resources := range limesServices {
+			srv := project.Services[limesServiceType(service, exists)]
+			if srv == nil {
+				continue
+			}
+			for resource := range resources {
+				if srv.Resources[resource] == nil || srv.Resources[resource].Quota == nil {
+					continue
+				}
+				quota[limesResourceKey(service, resource)] = int64(*srv.Resources[resource].Quota)
+			}
+		}
+		res = append(res, map[string]interface{}{
+			"project_id": project.UUID,
+			"name":       project.Name,
+			"quota":      quota,
+		})
+	}
+
+	return res
+}
+
+// limesOvercommitFactors returns the overcommit factors of the cluster
+// resources, i.e. the ratio between the effective and the raw capacity.
+// Resources without a raw capacity are not overcommitted and skipped.
+func limesOvercommitFactors(cluster *limes.ClusterReport) map[string]float64 {
+	res := make(map[string]float64)
+	exists := func(s string) bool { _, ok := cluster.Services[s]; return ok }
+	for service, resources := range limesServices {
+		srv := cluster.Services[limesServiceType(service, exists)]
+		if srv == nil {
+			continue
+		}
+		for resource := range resources {
+			r := srv.Resources[resource]
+			if r == nil || r.Capacity == nil || r.RawCapacity == nil || *r.RawCapacity == 0 {
+				continue
+			}
+			res[limesResourceKey(service, resource)] = float64(*r.Capacity) / float64(*r.RawCapacity)
+		}
+	}
+
+	return res
+}
+
+// limesQuotaValue returns the project resource quota in the unit used by the
+// schema, e.g. Gibibytes for the sharev2 capacity. The reported unit may
+// differ, when Limes changes the resource base unit.
+func limesQuotaValue(r *limes.ProjectResourceReport, unit limes.Unit) *uint64 {
+	if r.Quota == nil || r.Unit == unit {
+		return r.Quota
+	}
+
+	v, err := limes.ValueWithUnit{Value: *r.Quota, Unit: r.Unit}.ConvertTo(unit)
+	if err != nil {
+		log.Printf("[WARN] Failed to convert %s quota: %s", r.Name, err)
+		return r.Quota
+	}
+
+	return &v.Value
+}
+
 func toString(r interface{}) string {
 	switch v := r.(type) {
 	case *limes.ProjectResourceReport:
+		if v.Quota == nil {
+			return "no quota"
+		}
 		return limes.ValueWithUnit{Value: *v.Quota, Unit: v.Unit}.String()
 	case *limes.DomainResourceReport:
+		if v.DomainQuota == nil {
+			return "no quota"
+		}
 		return limes.ValueWithUnit{Value: *v.DomainQuota, Unit: v.Unit}.String()
 	}
 	return ""
@@ -78,44 +1013,180 @@ func sanitize(s string) string {
 	return strings.Replace(s, "-", "", -1)
 }
 
-func limesCCloudProjectQuotaV1WaitForProject(client *gophercloud.ServiceClient, domainID string, projectID string, services *limes.QuotaRequest, timeout time.Duration) error {
+func limesCCloudProjectQuotaV1WaitForProject(client, identity *gophercloud.ServiceClient, domainID string, projectID string, services *limes.QuotaRequest, timeout time.Duration) (*limes.ProjectReport, error) {
 	var msg string
 	var err error
+	var quota interface{}
 
 	// This condition is required, otherwise zero timeout will always raise:
 	// "timeout while waiting for state to become 'active'"
 	if timeout > 0 {
+		// the already initialized project doesn't require the retry loop
+		// and its initial delay
+		refresh := limesCCloudProjectQuotaV1GetQuota(client, identity, domainID, projectID, services, timeout)
+		if quota, msg, err := refresh(); err == nil && msg == "active" {
+			return quota.(*limes.ProjectReport), nil
+		}
+
 		// Retryable case, when timeout is set
 		waitForAgent := &resource.StateChangeConf{
 			Target:         []string{"active"},
-			Refresh:        limesCCloudProjectQuotaV1GetQuota(client, domainID, projectID, services, timeout),
+			Refresh:        refresh,
 			Timeout:        timeout,
 			Delay:          1 * time.Second,
 			MinTimeout:     1 * time.Second,
 			NotFoundChecks: 1000, // workaround for default 20 retries, when the resource is nil
 		}
-		_, err = waitForAgent.WaitForState()
+		quota, err = waitForAgent.WaitForState()
 	} else {
 		// When timeout is not set, just get the agent
-		_, msg, err = limesCCloudProjectQuotaV1GetQuota(client, domainID, projectID, services, timeout)()
+		quota, msg, err = limesCCloudProjectQuotaV1GetQuota(client, identity, domainID, projectID, services, timeout)()
 	}
 
 	if len(msg) > 0 && msg != "active" {
-		return fmt.Errorf(msg)
+		return nil, fmt.Errorf(msg)
 	}
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return quota.(*limes.ProjectReport), nil
+}
+
+// limesRetryDelay is the delay before the first retried Limes request and
+// limesRetryMinTimeout is the minimal interval between the retried requests.
+var (
+	limesRetryDelay      = 5 * time.Second
+	limesRetryMinTimeout = 10 * time.Second
+)
+
+// limesCCloudProjectQuotaV1Update updates the project quota. When the timeout
+// is set, the update is retried, while Limes rejects the writes with the 503
+// (Service Unavailable) response code during the maintenance.
+func limesCCloudProjectQuotaV1Update(client *gophercloud.ServiceClient, domainID string, projectID string, services limes.QuotaRequest, timeout time.Duration) ([]byte, error) {
+	update := func() (interface{}, string, error) {
+		warn, err := projects.Update(client, domainID, projectID, projects.UpdateOpts{Services: services}).Extract()
+		if err != nil {
+			if _, ok := err.(gophercloud.ErrDefault503); ok && timeout > 0 {
+				log.Printf("[DEBUG] Limes is in maintenance, retrying the %s/%s quota update: %s", domainID, projectID, err)
+				return err, "maintenance", nil
+			}
+			return nil, "", err
+		}
+		return warn, "applied", nil
+	}
+
+	if timeout == 0 {
+		warn, _, err := update()
+		if err != nil {
+			return nil, err
+		}
+		return warn.([]byte), nil
+	}
+
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"maintenance"},
+		Target:     []string{"applied"},
+		Refresh:    update,
+		Timeout:    timeout,
+		MinTimeout: limesRetryMinTimeout,
+	}
+	warn, err := stateConf.WaitForState()
+	if err != nil {
+		return nil, err
+	}
+
+	return warn.([]byte), nil
+}
+
+// limesCCloudProjectQuotaV1UpdateWaitForDomainQuota updates the project
+// quota. When the domain timeout is set and Limes rejects the quota, because
+// the domain quota is exhausted, the update is retried, once the domain can
+// provide the requested quota.
+func limesCCloudProjectQuotaV1UpdateWaitForDomainQuota(client *gophercloud.ServiceClient, domainID string, projectID string, services limes.QuotaRequest, timeout, domainTimeout time.Duration) ([]byte, error) {
+	warn, err := limesCCloudProjectQuotaV1Update(client, domainID, projectID, services, timeout)
+	e, ok := err.(gophercloud.ErrDefault409)
+	if !ok || domainTimeout == 0 {
+		return warn, err
+	}
+
+	// the domain quota may be increased in parallel
+	errs := limesParseDomainQuotaExceeded(e.Body, services)
+	if len(errs) == 0 {
+		return warn, err
+	}
+	if err := limesCCloudProjectQuotaV1WaitForDomainQuota(client, domainID, projectID, errs, domainTimeout); err != nil {
+		return nil, fmt.Errorf("Error waiting for Limes domain quota: %s", err)
+	}
+
+	return limesCCloudProjectQuotaV1Update(client, domainID, projectID, services, timeout)
+}
+
+// limesCCloudProjectQuotaV1WaitForDomainQuota waits until the domain quota
+// can provide the project quota, which was rejected by Limes, e.g. when the
+// domain quota is being increased in parallel.
+func limesCCloudProjectQuotaV1WaitForDomainQuota(client *gophercloud.ServiceClient, domainID string, projectID string, errs []error, timeout time.Duration) error {
+	refresh := func() (interface{}, string, error) {
+		project, err := projects.Get(client, domainID, projectID, projects.GetOpts{}).Extract()
+		if err != nil {
+			return nil, "", fmt.Errorf("Error getting Limes project: %s", err)
+		}
+		domain, err := domains.Get(client, domainID, domains.GetOpts{}).Extract()
+		if err != nil {
+			return nil, "", fmt.Errorf("Error getting Limes domain: %s", err)
+		}
+
+		for _, err := range errs {
+			e, ok := err.(limesDomainQuotaExceededError)
+			if !ok || project.Services[e.Service] == nil || domain.Services[e.Service] == nil {
+				continue
+			}
+			dr := domain.Services[e.Service].Resources[e.Resource]
+			max, ok := limesMaxProjectQuota(project.Services[e.Service].Resources[e.Resource], dr)
+			if !ok {
+				continue
+			}
+			if v, err := e.Requested.ConvertTo(dr.Unit); err == nil && v.Value > max {
+				log.Printf("[DEBUG] Waiting for the %s domain quota: %s", domainID, e)
+				return domain, "exceeded", nil
+			}
+		}
+
+		return domain, "available", nil
+	}
+
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"exceeded"},
+		Target:     []string{"available"},
+		Refresh:    refresh,
+		Timeout:    timeout,
+		Delay:      limesRetryDelay,
+		MinTimeout: limesRetryMinTimeout,
+	}
+	_, err := stateConf.WaitForState()
+
+	return err
 }
 
-func limesCCloudProjectQuotaV1GetQuota(client *gophercloud.ServiceClient, domainID string, projectID string, services *limes.QuotaRequest, timeout time.Duration) resource.StateRefreshFunc {
+// limesCCloudProjectQuotaV1GetQuota returns the refresh function, which waits
+// for the project to appear in Limes. The missing project is retried, when
+// timeout is set, unless the identity client reports that the project is
+// deleted or is being deleted. The identity client may be nil.
+func limesCCloudProjectQuotaV1GetQuota(client, identity *gophercloud.ServiceClient, domainID string, projectID string, services *limes.QuotaRequest, timeout time.Duration) resource.StateRefreshFunc {
 	return func() (interface{}, string, error) {
 		quota, err := projects.Get(client, domainID, projectID, projects.GetOpts{}).Extract()
 		if err != nil {
 			if _, ok := err.(gophercloud.ErrDefault404); ok && timeout > 0 {
+				// the deleted project will never appear in Limes
+				if identity != nil {
+					if _, err := identityProjects.Get(identity, projectID).Extract(); err != nil {
+						if _, ok := err.(gophercloud.ErrDefault404); ok {
+							return nil, "", fmt.Errorf("Unable to retrieve %s/%s ccloud_project_quota_v1: the project is deleted", domainID, projectID)
+						}
+						log.Printf("[DEBUG] Unable to retrieve %s identity project: %s", projectID, err)
+					}
+				}
 				// Retryable case, when timeout is set
 				return nil, fmt.Sprintf("Unable to retrieve %s/%s ccloud_project_quota_v1: %s", domainID, projectID, err), nil
 			}
@@ -0,0 +1,1017 @@
+package ccloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sapcc/limes"
+)
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
+
+func testLimesProjectReport(service, resource string, unit limes.Unit, quota uint64, usage uint64) *limes.ProjectReport {
+	return &limes.ProjectReport{
+		Services: limes.ProjectServiceReports{
+			service: {
+				ServiceInfo: limes.ServiceInfo{Type: service},
+				Resources: limes.ProjectResourceReports{
+					resource: {
+						ResourceInfo: limes.ResourceInfo{Name: resource, Unit: unit},
+						Quota:        uint64Ptr(quota),
+						Usage:        usage,
+					},
+				},
+			},
+		},
+	}
+}
+
+func testLimesDomainReport(service, resource string, unit limes.Unit, domainQuota, projectsQuota uint64) *limes.DomainReport {
+	return &limes.DomainReport{
+		Services: limes.DomainServiceReports{
+			service: {
+				ServiceInfo: limes.ServiceInfo{Type: service},
+				Resources: limes.DomainResourceReports{
+					resource: {
+						ResourceInfo:  limes.ResourceInfo{Name: resource, Unit: unit},
+						DomainQuota:   uint64Ptr(domainQuota),
+						ProjectsQuota: uint64Ptr(projectsQuota),
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestLimesClampQuotaRequest(t *testing.T) {
+	// the project has 2048 MiB and the domain has 2048 MiB of the headroom,
+	// i.e. the maximum project quota is 4096 MiB
+	project := testLimesProjectReport("compute", "ram", limes.UnitMebibytes, 2048, 0)
+	domain := testLimesDomainReport("compute", "ram", limes.UnitMebibytes, 10240, 8192)
+
+	cases := []struct {
+		name     string
+		request  limes.ValueWithUnit
+		expected limes.ValueWithUnit
+	}{
+		{"same unit below", limes.ValueWithUnit{Value: 3072, Unit: limes.UnitMebibytes}, limes.ValueWithUnit{Value: 3072, Unit: limes.UnitMebibytes}},
+		{"same unit above", limes.ValueWithUnit{Value: 8192, Unit: limes.UnitMebibytes}, limes.ValueWithUnit{Value: 4096, Unit: limes.UnitMebibytes}},
+		{"larger unit below", limes.ValueWithUnit{Value: 3, Unit: limes.UnitGibibytes}, limes.ValueWithUnit{Value: 3, Unit: limes.UnitGibibytes}},
+		{"larger unit equal", limes.ValueWithUnit{Value: 4, Unit: limes.UnitGibibytes}, limes.ValueWithUnit{Value: 4, Unit: limes.UnitGibibytes}},
+		{"larger unit above", limes.ValueWithUnit{Value: 10, Unit: limes.UnitGibibytes}, limes.ValueWithUnit{Value: 4, Unit: limes.UnitGibibytes}},
+		{"smaller unit above", limes.ValueWithUnit{Value: 8 << 20, Unit: limes.UnitKibibytes}, limes.ValueWithUnit{Value: 4 << 20, Unit: limes.UnitKibibytes}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			services := limes.QuotaRequest{
+				"compute": {Resources: limes.ResourceQuotaRequest{"ram": c.request}},
+			}
+			limesClampQuotaRequest(services, project, domain)
+			if v := services["compute"].Resources["ram"]; v != c.expected {
+				t.Fatalf("expected %s, got %s", c.expected, v)
+			}
+		})
+	}
+}
+
+func TestLimesQuotaBelowUsage(t *testing.T) {
+	r := testLimesProjectReport("compute", "ram", limes.UnitMebibytes, 4096, 2048).Services["compute"].Resources["ram"]
+
+	cases := []struct {
+		request  limes.ValueWithUnit
+		expected bool
+	}{
+		{limes.ValueWithUnit{Value: 1024, Unit: limes.UnitMebibytes}, true},
+		{limes.ValueWithUnit{Value: 2048, Unit: limes.UnitMebibytes}, false},
+		{limes.ValueWithUnit{Value: 1, Unit: limes.UnitGibibytes}, true},
+		{limes.ValueWithUnit{Value: 2, Unit: limes.UnitGibibytes}, false},
+		{limes.ValueWithUnit{Value: 3, Unit: limes.UnitGibibytes}, false},
+		{limes.ValueWithUnit{Value: 512, Unit: limes.UnitNone}, false},
+	}
+
+	for _, c := range cases {
+		if v := limesQuotaBelowUsage(c.request, r); v != c.expected {
+			t.Errorf("%s: expected %t, got %t", c.request, c.expected, v)
+		}
+	}
+}
+
+func TestLimesResolveDynamicResources(t *testing.T) {
+	project := testLimesProjectReport("object-store", "capacity", limes.UnitBytes, 0, 0)
+	project.Services["object-store"].Resources["capacity_custom"] = &limes.ProjectResourceReport{
+		ResourceInfo: limes.ResourceInfo{Name: "capacity_custom", Unit: limes.UnitMebibytes},
+	}
+
+	cases := []struct {
+		name     string
+		json     string
+		expected limes.ResourceQuotaRequest
+		err      string
+	}{
+		{
+			"static resource",
+			`{"object-store":{"capacity":1024}}`,
+			limes.ResourceQuotaRequest{"capacity": {Value: 1024, Unit: limes.UnitBytes}},
+			"",
+		},
+		{
+			"region specific resource",
+			`{"object-store":{"capacity":1024,"capacity_custom":5}}`,
+			limes.ResourceQuotaRequest{
+				"capacity":        {Value: 1024, Unit: limes.UnitBytes},
+				"capacity_custom": {Value: 5, Unit: limes.UnitMebibytes},
+			},
+			"",
+		},
+		{
+			"resource missing in the report",
+			`{"object-store":{"capacity_other":5}}`,
+			nil,
+			`Unknown "capacity_other" resource of the "object-store" service`,
+		},
+		{
+			"unknown resource of the static service",
+			`{"compute":{"cores_custom":5}}`,
+			nil,
+			`Unknown "cores_custom" resource of the "compute" service in the quota JSON`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			services, err := expandLimesQuotaJSON(c.json)
+			if err == nil {
+				err = limesResolveDynamicResources(services, project)
+			}
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expected the %q error, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if v := services["object-store"].Resources; !reflect.DeepEqual(v, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, v)
+			}
+		})
+	}
+}
+
+func TestLimesUsagePercent(t *testing.T) {
+	cases := []struct {
+		name     string
+		quota    *uint64
+		usage    uint64
+		expected float64
+		ok       bool
+	}{
+		{"partial usage", uint64Ptr(8), 2, 25, true},
+		{"full usage", uint64Ptr(4), 4, 100, true},
+		{"overusage", uint64Ptr(2), 3, 150, true},
+		{"no usage", uint64Ptr(10), 0, 0, true},
+		{"zero quota", uint64Ptr(0), 0, 0, false},
+		{"zero quota with usage", uint64Ptr(0), 5, 0, false},
+		{"unlimited quota", nil, 5, 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, ok := limesUsagePercent(&limes.ProjectResourceReport{Quota: c.quota, Usage: c.usage})
+			if v != c.expected || ok != c.ok {
+				t.Errorf("expected %v (%t), got %v (%t)", c.expected, c.ok, v, ok)
+			}
+		})
+	}
+}
+
+func TestLimesDomainUtilizationPercent(t *testing.T) {
+	cases := []struct {
+		name        string
+		domainQuota *uint64
+		usage       uint64
+		expected    float64
+		ok          bool
+	}{
+		{"normal", uint64Ptr(200), 50, 25, true},
+		{"overcommitted", uint64Ptr(100), 150, 150, true},
+		{"zero quota", uint64Ptr(0), 0, 0, false},
+		{"zero quota with usage", uint64Ptr(0), 10, 0, false},
+		{"unlimited quota", nil, 10, 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, ok := limesDomainUtilizationPercent(&limes.DomainResourceReport{DomainQuota: c.domainQuota, Usage: c.usage})
+			if v != c.expected || ok != c.ok {
+				t.Errorf("expected %v (%t), got %v (%t)", c.expected, c.ok, v, ok)
+			}
+		})
+	}
+}
+
+func TestLimesPlanDomainHeadroom(t *testing.T) {
+	ram := func(before, after uint64, unit limes.Unit) (limes.QuotaRequest, limes.QuotaRequest) {
+		return limes.QuotaRequest{"compute": {Resources: limes.ResourceQuotaRequest{"ram": {Value: before, Unit: unit}}}},
+			limes.QuotaRequest{"compute": {Resources: limes.ResourceQuotaRequest{"ram": {Value: after, Unit: unit}}}}
+	}
+	unlimited := testLimesDomainReport("compute", "ram", limes.UnitMebibytes, 0, 0)
+	unlimited.Services["compute"].Resources["ram"].DomainQuota = nil
+
+	cases := []struct {
+		name     string
+		domain   *limes.DomainReport
+		unit     limes.Unit
+		before   uint64
+		after    uint64
+		expected map[string]int64
+	}{
+		{"increase", testLimesDomainReport("compute", "ram", limes.UnitMebibytes, 10240, 8192), limes.UnitMebibytes, 1024, 1536, map[string]int64{"compute/ram": 1536}},
+		{"increase in larger unit", testLimesDomainReport("compute", "ram", limes.UnitMebibytes, 10240, 8192), limes.UnitGibibytes, 1, 2, map[string]int64{"compute/ram": 1024}},
+		{"increase above headroom", testLimesDomainReport("compute", "ram", limes.UnitMebibytes, 10240, 8192), limes.UnitMebibytes, 1024, 4096, map[string]int64{"compute/ram": -1024}},
+		{"decrease", testLimesDomainReport("compute", "ram", limes.UnitMebibytes, 10240, 8192), limes.UnitMebibytes, 1024, 512, map[string]int64{"compute/ram": 2048}},
+		{"unchanged", testLimesDomainReport("compute", "ram", limes.UnitMebibytes, 10240, 8192), limes.UnitMebibytes, 1024, 1024, map[string]int64{"compute/ram": 2048}},
+		{"unlimited domain quota", unlimited, limes.UnitMebibytes, 1024, 4096, map[string]int64{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			before, after := ram(c.before, c.after, c.unit)
+			headroom := limesDomainHeadroom(c.domain)
+			limesPlanDomainHeadroom(headroom, c.domain, before, after)
+			if !reflect.DeepEqual(headroom, c.expected) {
+				t.Errorf("expected %v headroom, got %v", c.expected, headroom)
+			}
+			if increased := c.after > c.before; limesQuotaIncreased(before, after) != increased {
+				t.Errorf("expected the increased flag to be %t", increased)
+			}
+		})
+	}
+}
+
+func TestLimesCheckQuotaBelowUsage(t *testing.T) {
+	project := testLimesProjectReport("compute", "cores", limes.UnitNone, 10, 8)
+	project.Services["volumev3"] = testLimesProjectReport("volumev3", "capacity", limes.UnitGibibytes, 100, 50).Services["volumev3"]
+	shrink := limes.QuotaRequest{
+		"compute":  {Resources: limes.ResourceQuotaRequest{"cores": {Value: 4, Unit: limes.UnitNone}}},
+		"volumev3": {Resources: limes.ResourceQuotaRequest{"capacity": {Value: 100, Unit: limes.UnitGibibytes}}},
+	}
+	aliasShrink := limes.QuotaRequest{
+		"volumev3": {Resources: limes.ResourceQuotaRequest{"capacity": {Value: 10, Unit: limes.UnitGibibytes}}},
+	}
+
+	cases := []struct {
+		name           string
+		services       limes.QuotaRequest
+		ignoreUsageFor []interface{}
+		err            string
+	}{
+		{"shrink below usage", shrink, nil, "compute/cores quota 4 is below the current usage 8"},
+		{"ignored resource", shrink, []interface{}{"compute/cores"}, ""},
+		{"other ignored resource", shrink, []interface{}{"compute/instances"}, "compute/cores quota 4"},
+		{"ignored alias", aliasShrink, []interface{}{"volumev2/capacity"}, ""},
+		{"not ignored alias", aliasShrink, nil, "volumev3/capacity quota 10 GiB is below the current usage 50 GiB"},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, map[string]interface{}{
+			"ignore_usage_for": c.ignoreUsageFor,
+		})
+		err := limesCheckQuotaBelowUsage(c.services, project, limesIgnoreUsageFor(d))
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", c.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: expected the %q error, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestExpandLimesQuotaMap(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    map[string]interface{}
+		expected limes.QuotaRequest
+		err      string
+	}{
+		{
+			name:  "valid",
+			input: map[string]interface{}{"compute/cores": 10, "compute/ram": "20480", "network/ports": float64(100)},
+			expected: limes.QuotaRequest{
+				"compute": {Resources: limes.ResourceQuotaRequest{
+					"cores": {Value: 10, Unit: limes.UnitNone},
+					"ram":   {Value: 20480, Unit: limes.UnitMebibytes},
+				}},
+				"network": {Resources: limes.ResourceQuotaRequest{
+					"ports": {Value: 100, Unit: limes.UnitNone},
+				}},
+			},
+		},
+		{
+			name:  "sanitized service name",
+			input: map[string]interface{}{"objectstore/capacity": "1125899906842625"},
+			expected: limes.QuotaRequest{
+				"object-store": {Resources: limes.ResourceQuotaRequest{
+					"capacity": {Value: 1125899906842625, Unit: limes.UnitBytes},
+				}},
+			},
+		},
+		{name: "missing resource", input: map[string]interface{}{"compute": 10}, err: `Invalid "compute" quota key, expected the "service/resource" format`},
+		{name: "nested key", input: map[string]interface{}{"compute/cores/total": 10}, err: `Unknown "cores/total" resource of the "compute" service`},
+		{name: "unknown service", input: map[string]interface{}{"foo/cores": 10}, err: `Unknown "foo" service in the quota map`},
+		{name: "unknown resource", input: map[string]interface{}{"compute/foo": 10}, err: `Unknown "foo" resource of the "compute" service`},
+		{name: "negative", input: map[string]interface{}{"compute/cores": -1}, err: "must not be negative"},
+		{name: "fractional", input: map[string]interface{}{"compute/cores": 1.5}, err: "must be an integer"},
+		{name: "unit suffix", input: map[string]interface{}{"compute/ram": "20 GiB"}, err: `Invalid "compute/ram" quota value`},
+		{name: "unit suffixed key", input: map[string]interface{}{"compute/ram_gib": 20}, err: `Unknown "ram_gib" resource`},
+		{name: "unexpected type", input: map[string]interface{}{"compute/cores": true}, err: `Invalid "compute/cores" quota value`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := expandLimesQuotaMap(c.input)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected the %q error, got %v (%v)", c.err, err, v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(v, c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, v)
+			}
+		})
+	}
+}
+
+func TestValidateLimesQuotaMapUnknown(t *testing.T) {
+	_, errs := validateLimesQuotaMap(map[string]interface{}{"compute/cores": unknownVariableValue, "compute/ram": 1024}, "quota")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	_, errs = validateLimesQuotaMap(map[string]interface{}{"compute/cores": unknownVariableValue, "compute/foo": 1024}, "quota")
+	if len(errs) == 0 {
+		t.Fatal("expected an error for the unknown resource")
+	}
+
+	for key, expected := range map[string]string{
+		"compute":       `"quota": Invalid "compute" quota key`,
+		"foo/cores":     `"quota": Unknown "foo" service`,
+		"compute/cores": "",
+	} {
+		_, errs := validateLimesQuotaMap(map[string]interface{}{key: 1}, "quota")
+		if expected == "" {
+			if len(errs) > 0 {
+				t.Errorf("%s: unexpected errors: %v", key, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), expected) {
+			t.Errorf("%s: expected the %q error, got %v", key, expected, errs)
+		}
+	}
+}
+
+func TestLimesCCloudProjectQuotaV1GetQuotaDeletedProject(t *testing.T) {
+	limesServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer limesServer.Close()
+
+	keystoneServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/existing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"existing","domain_id":"domain"}}`))
+	}))
+	defer keystoneServer.Close()
+
+	limesClient := testServiceClient(limesServer)
+	identity := testServiceClient(keystoneServer)
+
+	// the project is deleted, the refresh fails immediately
+	_, state, err := limesCCloudProjectQuotaV1GetQuota(limesClient, identity, "domain", "deleted", &limes.QuotaRequest{}, time.Minute)()
+	if err == nil {
+		t.Fatalf("expected an error, got %q state", state)
+	}
+
+	// the project is not yet known to Limes, the refresh is retried
+	_, state, err = limesCCloudProjectQuotaV1GetQuota(limesClient, identity, "domain", "existing", &limes.QuotaRequest{}, time.Minute)()
+	if err != nil || state == "active" {
+		t.Fatalf("expected a pending state, got %q state and %v error", state, err)
+	}
+
+	// without the identity client the missing project is retried
+	_, state, err = limesCCloudProjectQuotaV1GetQuota(limesClient, nil, "domain", "deleted", &limes.QuotaRequest{}, time.Minute)()
+	if err != nil || state == "active" {
+		t.Fatalf("expected a pending state, got %q state and %v error", state, err)
+	}
+}
+
+func TestLimesCCloudProjectQuotaV1WaitForProjectSkip(t *testing.T) {
+	var calls int
+	found := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","services":[{"type":"compute","resources":[{"name":"cores","quota":10}]}]}}`))
+	}))
+	defer server.Close()
+
+	client := testServiceClient(server)
+	services := limes.QuotaRequest{
+		"compute": {Resources: limes.ResourceQuotaRequest{"cores": {Value: 10, Unit: limes.UnitNone}}},
+	}
+
+	// the wait is skipped, the missing project is not retried
+	start := time.Now()
+	if _, err := limesCCloudProjectQuotaV1WaitForProject(client, nil, "d1", "p1", &services, 0); err == nil {
+		t.Fatal("expected the 404 error")
+	}
+	if calls != 1 {
+		t.Errorf("expected a single request, got %d", calls)
+	}
+
+	// the initialized project is returned without the retry loop delay
+	calls = 0
+	found = true
+	quota, err := limesCCloudProjectQuotaV1WaitForProject(client, nil, "d1", "p1", &services, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if quota.UUID != "p1" {
+		t.Errorf("expected the p1 project, got %q", quota.UUID)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single request, got %d", calls)
+	}
+	if v := time.Since(start); v > time.Second {
+		t.Errorf("expected no retry delay, took %s", v)
+	}
+}
+
+func TestLimesAliasQuotaRequest(t *testing.T) {
+	request := limes.QuotaRequest{
+		"compute":  {Resources: limes.ResourceQuotaRequest{"cores": {Value: 10}}},
+		"volumev2": {Resources: limes.ResourceQuotaRequest{"volumes": {Value: 5}}},
+	}
+
+	cases := []struct {
+		name     string
+		services []string
+		expected []string
+	}{
+		{"default service", []string{"compute", "volumev2"}, []string{"compute", "volumev2"}},
+		{"volumev3 region", []string{"compute", "volumev3"}, []string{"compute", "volumev3"}},
+		{"volume region", []string{"compute", "volume"}, []string{"compute", "volume"}},
+		{"missing service", []string{"compute"}, []string{"compute", "volumev2"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exists := func(s string) bool {
+				for _, v := range c.services {
+					if v == s {
+						return true
+					}
+				}
+				return false
+			}
+
+			res := limesAliasQuotaRequest(request, exists)
+			var services []string
+			for service := range res {
+				services = append(services, service)
+			}
+			sort.Strings(services)
+			if !reflect.DeepEqual(services, c.expected) {
+				t.Fatalf("expected %v services, got %v", c.expected, services)
+			}
+		})
+	}
+}
+
+func TestExpandLimesQuotaJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected limes.QuotaRequest
+		err      bool
+	}{
+		{
+			name:  "two services",
+			input: `{"compute":{"cores":10,"ram":20480},"object-store":{"capacity":1073741824}}`,
+			expected: limes.QuotaRequest{
+				"compute": {Resources: limes.ResourceQuotaRequest{
+					"cores": {Value: 10, Unit: limes.UnitNone},
+					"ram":   {Value: 20480, Unit: limes.UnitMebibytes},
+				}},
+				"object-store": {Resources: limes.ResourceQuotaRequest{
+					"capacity": {Value: 1073741824, Unit: limes.UnitBytes},
+				}},
+			},
+		},
+		{
+			// the values use the schema units, not the base units
+			name:  "mebibytes and gibibytes",
+			input: `{"compute":{"ram":10240},"volumev2":{"capacity":100},"sharev2":{"share_capacity":50}}`,
+			expected: limes.QuotaRequest{
+				"compute": {Resources: limes.ResourceQuotaRequest{
+					"ram": {Value: 10240, Unit: limes.UnitMebibytes},
+				}},
+				"volumev2": {Resources: limes.ResourceQuotaRequest{
+					"capacity": {Value: 100, Unit: limes.UnitGibibytes},
+				}},
+				"sharev2": {Resources: limes.ResourceQuotaRequest{
+					"share_capacity": {Value: 50, Unit: limes.UnitGibibytes},
+				}},
+			},
+		},
+		{
+			name:  "sanitized service name",
+			input: `{"objectstore":{"capacity":1}}`,
+			expected: limes.QuotaRequest{
+				"object-store": {Resources: limes.ResourceQuotaRequest{
+					"capacity": {Value: 1, Unit: limes.UnitBytes},
+				}},
+			},
+		},
+		{
+			// the unit of the dynamic resource is resolved from the report
+			name:  "dynamic resource",
+			input: `{"object-store":{"capacity_custom":1}}`,
+			expected: limes.QuotaRequest{
+				"object-store": {Resources: limes.ResourceQuotaRequest{
+					"capacity_custom": {Value: 1},
+				}},
+			},
+		},
+		{name: "invalid JSON", input: `{"compute":`, err: true},
+		{name: "unknown service", input: `{"foo":{"cores":1}}`, err: true},
+		{name: "unknown resource", input: `{"compute":{"foo":1}}`, err: true},
+		{name: "negative value", input: `{"compute":{"cores":-1}}`, err: true},
+		{name: "fractional value", input: `{"compute":{"cores":1.5}}`, err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := expandLimesQuotaJSON(c.input)
+			if c.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(v, c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, v)
+			}
+		})
+	}
+}
+
+func TestLimesCheckQuotaRatios(t *testing.T) {
+	defer log.SetOutput(os.Stderr)
+
+	project := testLimesProjectReport("compute", "cores", limes.UnitNone, 10, 0)
+
+	cases := []struct {
+		name     string
+		request  limes.QuotaRequest
+		expected bool
+	}{
+		{
+			name: "sensible ratio",
+			request: limes.QuotaRequest{"compute": {Resources: limes.ResourceQuotaRequest{
+				"cores": {Value: 10, Unit: limes.UnitNone},
+				"ram":   {Value: 40960, Unit: limes.UnitMebibytes},
+			}}},
+		},
+		{
+			// the ram is specified in GiB instead of MiB
+			name: "suspicious ratio",
+			request: limes.QuotaRequest{"compute": {Resources: limes.ResourceQuotaRequest{
+				"ram": {Value: 40, Unit: limes.UnitMebibytes},
+			}}},
+			expected: true,
+		},
+		{
+			name: "unrelated change",
+			request: limes.QuotaRequest{"network": {Resources: limes.ResourceQuotaRequest{
+				"ports": {Value: 1, Unit: limes.UnitNone},
+			}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			limesCheckQuotaRatios(c.request, project)
+			if v := strings.Contains(buf.String(), "[WARN]"); v != c.expected {
+				t.Fatalf("expected the warning %t, got %q", c.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestLimesSplitQuotaRequest(t *testing.T) {
+	project := &limes.ProjectReport{
+		Services: limes.ProjectServiceReports{
+			"compute": {
+				ServiceInfo: limes.ServiceInfo{Type: "compute"},
+				Resources: limes.ProjectResourceReports{
+					"cores": {ResourceInfo: limes.ResourceInfo{Name: "cores"}, Quota: uint64Ptr(10)},
+					"ram":   {ResourceInfo: limes.ResourceInfo{Name: "ram", Unit: limes.UnitMebibytes}, Quota: uint64Ptr(10240)},
+				},
+			},
+			"network": {
+				ServiceInfo: limes.ServiceInfo{Type: "network"},
+				Resources: limes.ProjectResourceReports{
+					"ports": {ResourceInfo: limes.ResourceInfo{Name: "ports"}, Quota: uint64Ptr(100)},
+				},
+			},
+		},
+	}
+
+	cores := func(v uint64) limes.ValueWithUnit { return limes.ValueWithUnit{Value: v, Unit: limes.UnitNone} }
+	ports := cores
+
+	cases := []struct {
+		name     string
+		request  limes.QuotaRequest
+		expected []limes.QuotaRequest
+	}{
+		{
+			name: "increases only",
+			request: limes.QuotaRequest{
+				"compute": {Resources: limes.ResourceQuotaRequest{"cores": cores(20)}},
+				"network": {Resources: limes.ResourceQuotaRequest{"ports": ports(200)}},
+			},
+			expected: []limes.QuotaRequest{{
+				"compute": {Resources: limes.ResourceQuotaRequest{"cores": cores(20)}},
+				"network": {Resources: limes.ResourceQuotaRequest{"ports": ports(200)}},
+			}},
+		},
+		{
+			// the decrease belongs to the other service, one request is enough
+			name: "increase and decrease in different services",
+			request: limes.QuotaRequest{
+				"compute": {Resources: limes.ResourceQuotaRequest{"cores": cores(20)}},
+				"network": {Resources: limes.ResourceQuotaRequest{"ports": ports(50)}},
+			},
+			expected: []limes.QuotaRequest{{
+				"compute": {Resources: limes.ResourceQuotaRequest{"cores": cores(20)}},
+				"network": {Resources: limes.ResourceQuotaRequest{"ports": ports(50)}},
+			}},
+		},
+		{
+			// the ram is decreased, since 8 GiB is below 10240 MiB
+			name: "increase and decrease in the same service",
+			request: limes.QuotaRequest{
+				"compute": {Resources: limes.ResourceQuotaRequest{
+					"cores": cores(20),
+					"ram":   {Value: 8, Unit: limes.UnitGibibytes},
+				}},
+			},
+			expected: []limes.QuotaRequest{
+				{"compute": {Resources: limes.ResourceQuotaRequest{"cores": cores(20)}}},
+				{"compute": {Resources: limes.ResourceQuotaRequest{"ram": {Value: 8, Unit: limes.UnitGibibytes}}}},
+			},
+		},
+		{
+			name: "decreases only",
+			request: limes.QuotaRequest{
+				"compute": {Resources: limes.ResourceQuotaRequest{"cores": cores(5)}},
+			},
+			expected: []limes.QuotaRequest{{
+				"compute": {Resources: limes.ResourceQuotaRequest{"cores": cores(5)}},
+			}},
+		},
+		{
+			name:    "empty request",
+			request: limes.QuotaRequest{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := limesSplitQuotaRequest(c.request, project)
+			if !reflect.DeepEqual(v, c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, v)
+			}
+		})
+	}
+}
+
+func TestLimesParseDomainQuotaExceeded(t *testing.T) {
+	services := limes.QuotaRequest{
+		"compute": {Resources: limes.ResourceQuotaRequest{
+			"cores": {Value: 100, Unit: limes.UnitNone},
+			"ram":   {Value: 204800, Unit: limes.UnitMebibytes},
+		}},
+	}
+
+	body := []byte("cannot change compute/cores quota: domain quota exceeded (maximum acceptable project quota is 60)\n" +
+		"cannot change compute/ram quota: domain quota exceeded (maximum acceptable project quota is 100 GiB)\n" +
+		"cannot change network/ports quota: domain quota exceeded (maximum acceptable project quota is 10)\n" +
+		"cannot change compute/instances quota: quota may not be lower than current usage\n")
+
+	errs := limesParseDomainQuotaExceeded(body, services)
+	expected := []error{
+		limesDomainQuotaExceededError{
+			Service:   "compute",
+			Resource:  "cores",
+			Requested: limes.ValueWithUnit{Value: 100, Unit: limes.UnitNone},
+			Maximum:   limes.ValueWithUnit{Value: 60, Unit: limes.UnitNone},
+		},
+		limesDomainQuotaExceededError{
+			Service:   "compute",
+			Resource:  "ram",
+			Requested: limes.ValueWithUnit{Value: 204800, Unit: limes.UnitMebibytes},
+			Maximum:   limes.ValueWithUnit{Value: 102400, Unit: limes.UnitMebibytes},
+		},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Fatalf("expected %v, got %v", expected, errs)
+	}
+
+	if v, expected := errs[0].Error(), "domain quota exhausted for compute/cores: requested 100, maximum acceptable project quota is 60, shortfall is 40"; v != expected {
+		t.Errorf("expected %q error, got %q", expected, v)
+	}
+
+	if errs := limesParseDomainQuotaExceeded([]byte("cannot change compute/cores quota: forbidden"), services); len(errs) != 0 {
+		t.Errorf("expected no errors for the unrelated rejection, got %v", errs)
+	}
+}
+
+func TestLimesNotifyQuotaChange(t *testing.T) {
+	var received []limesQuotaNotification
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		headers = r.Header
+		var v limesQuotaNotification
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			t.Errorf("failed to decode the notification: %s", err)
+		}
+		received = append(received, v)
+	}))
+	defer server.Close()
+
+	services := limes.QuotaRequest{
+		"compute": {Resources: limes.ResourceQuotaRequest{"cores": {Value: 20, Unit: limes.UnitNone}}},
+	}
+	before := testLimesProjectReport("compute", "cores", limes.UnitNone, 10, 0)
+	after := testLimesProjectReport("compute", "cores", limes.UnitNone, 20, 0)
+	changes := limesQuotaChanges(services, before, after)
+
+	limesNotifyQuotaChange(server.URL+"/hook", "d1", "p1", changes)
+
+	expected := []limesQuotaNotification{{
+		DomainID:  "d1",
+		ProjectID: "p1",
+		Changes:   []limesQuotaChange{{Service: "compute", Resource: "cores", Unit: limes.UnitNone, Old: uint64Ptr(10), New: uint64Ptr(20)}},
+	}}
+	if !reflect.DeepEqual(received, expected) {
+		t.Fatalf("expected %+v notification, got %+v", expected, received)
+	}
+	for _, h := range []string{"X-Auth-Token", serviceTokenHeader, requestIDHeader} {
+		if v := headers.Get(h); v != "" {
+			t.Errorf("unexpected %q %s header in the notification", v, h)
+		}
+	}
+
+	defer log.SetOutput(os.Stderr)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	// the unchanged quota is not notified
+	limesNotifyQuotaChange(server.URL+"/hook", "d1", "p1", limesQuotaChanges(services, after, after))
+	if len(received) != 1 {
+		t.Errorf("expected no notification for the unchanged quota, got %+v", received[1:])
+	}
+
+	// the webhook failures are logged only
+	limesNotifyQuotaChange(server.URL+"/fail", "d1", "p1", changes)
+	limesNotifyQuotaChange("http://127.0.0.1:0/hook", "d1", "p1", changes)
+	if v := strings.Count(buf.String(), "[WARN] Failed to send the quota change notification"); v != 2 {
+		t.Errorf("expected 2 notification warnings, got %d: %s", v, buf.String())
+	}
+}
+
+func TestLimesEmitQuotaMetrics(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start the collector: %s", err)
+	}
+	defer conn.Close()
+
+	changes := []limesQuotaChange{
+		{Service: "compute", Resource: "cores", Old: uint64Ptr(10), New: uint64Ptr(20)},
+		{Service: "network", Resource: "ports", Old: uint64Ptr(100), New: uint64Ptr(50)},
+		{Service: "dns", Resource: "zones", New: uint64Ptr(5)},
+	}
+	limesEmitQuotaMetrics(conn.LocalAddr().String(), changes)
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to receive the metrics: %s", err)
+	}
+
+	expected := "ccloud.project_quota.resources_changed:3|g\nccloud.project_quota.total_delta:-35|g"
+	if v := string(buf[:n]); v != expected {
+		t.Fatalf("expected %q metrics, got %q", expected, v)
+	}
+
+	// the failure to send the metrics is not fatal
+	limesEmitQuotaMetrics("invalid endpoint", changes)
+}
+
+func TestLimesCCloudProjectQuotaV1UpdateMaintenance(t *testing.T) {
+	defer func(v time.Duration) { limesRetryMinTimeout = v }(limesRetryMinTimeout)
+	limesRetryMinTimeout = 10 * time.Millisecond
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != "PUT" || r.URL.Path != "/domains/d1/projects/p1" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("warning"))
+	}))
+	defer server.Close()
+
+	client := testServiceClient(server)
+	services := limes.QuotaRequest{
+		"compute": {Resources: limes.ResourceQuotaRequest{"cores": {Value: 10, Unit: limes.UnitNone}}},
+	}
+
+	// the maintenance is not retried without the timeout
+	if _, err := limesCCloudProjectQuotaV1Update(client, "d1", "p1", services, 0); err == nil {
+		t.Fatal("expected the 503 error")
+	} else if _, ok := err.(gophercloud.ErrDefault503); !ok {
+		t.Fatalf("expected the 503 error, got %T: %s", err, err)
+	}
+
+	warn, err := limesCCloudProjectQuotaV1Update(client, "d1", "p1", services, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(warn) != "warning" {
+		t.Errorf("expected the \"warning\" body, got %q", warn)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 requests, got %d", calls)
+	}
+}
+
+func TestLimesCCloudProjectQuotaV1UpdateWaitForDomainQuota(t *testing.T) {
+	defer func(delay, min time.Duration) {
+		limesRetryDelay, limesRetryMinTimeout = delay, min
+	}(limesRetryDelay, limesRetryMinTimeout)
+	limesRetryDelay, limesRetryMinTimeout = 0, 10*time.Millisecond
+
+	// the domain quota is increased in parallel, after the first domain read
+	project := testLimesProjectReport("compute", "cores", limes.UnitNone, 10, 0)
+	exhausted := testLimesDomainReport("compute", "cores", limes.UnitNone, 100, 100)
+	increased := testLimesDomainReport("compute", "cores", limes.UnitNone, 200, 100)
+
+	var puts, domainGets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/domains/d1/projects/p1":
+			puts++
+			if domainGets < 2 {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte("cannot change compute/cores quota: domain quota exceeded (maximum acceptable project quota is 10)"))
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		case r.Method == "GET" && r.URL.Path == "/domains/d1/projects/p1":
+			body = map[string]interface{}{"project": project}
+		case r.Method == "GET" && r.URL.Path == "/domains/d1":
+			domainGets++
+			if domainGets < 2 {
+				body = map[string]interface{}{"domain": exhausted}
+			} else {
+				body = map[string]interface{}{"domain": increased}
+			}
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	client := testServiceClient(server)
+	services := limes.QuotaRequest{
+		"compute": {Resources: limes.ResourceQuotaRequest{"cores": {Value: 50, Unit: limes.UnitNone}}},
+	}
+
+	// the rejection is returned, when the wait is disabled
+	if _, err := limesCCloudProjectQuotaV1UpdateWaitForDomainQuota(client, "d1", "p1", services, 0, 0); err == nil {
+		t.Fatal("expected the 409 error")
+	} else if _, ok := err.(gophercloud.ErrDefault409); !ok {
+		t.Fatalf("expected the 409 error, got %T: %s", err, err)
+	}
+
+	puts = 0
+	if _, err := limesCCloudProjectQuotaV1UpdateWaitForDomainQuota(client, "d1", "p1", services, 0, time.Minute); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if puts != 2 {
+		t.Errorf("expected 2 update requests, got %d", puts)
+	}
+	if domainGets != 2 {
+		t.Errorf("expected 2 domain requests, got %d", domainGets)
+	}
+}
+
+func TestLimesFlattenDomainProjects(t *testing.T) {
+	project := testLimesProjectReport("object-store", "capacity", limes.UnitBytes, 1<<60+1, 0)
+	project.UUID = "p1"
+	project.Name = "project"
+
+	expected := []map[string]interface{}{{
+		"project_id": "p1",
+		"name":       "project",
+		"quota":      map[string]int64{"objectstore/capacity": 1<<60 + 1},
+	}}
+	if v := limesFlattenDomainProjects([]limes.ProjectReport{*project}); !reflect.DeepEqual(v, expected) {
+		t.Fatalf("expected %v, got %v", expected, v)
+	}
+}
+
+func TestLimesEffectiveQuota(t *testing.T) {
+	bursting := &limes.ProjectBurstingInfo{Enabled: true, Multiplier: 0.2}
+	disabled := &limes.ProjectBurstingInfo{Enabled: false, Multiplier: 0.2}
+
+	cases := []struct {
+		name     string
+		bursting *limes.ProjectBurstingInfo
+		quota    *uint64
+		usable   *uint64
+		expected *uint64
+	}{
+		{"no bursting", nil, uint64Ptr(100), nil, uint64Ptr(100)},
+		{"bursting disabled", disabled, uint64Ptr(100), nil, uint64Ptr(100)},
+		{"bursting enabled", bursting, uint64Ptr(100), nil, uint64Ptr(120)},
+		{"usable quota", bursting, uint64Ptr(100), uint64Ptr(110), uint64Ptr(110)},
+		{"no quota", bursting, nil, nil, nil},
+	}
+
+	for _, c := range cases {
+		project := &limes.ProjectReport{Bursting: c.bursting}
+		r := &limes.ProjectResourceReport{Quota: c.quota, UsableQuota: c.usable}
+		v := limesEffectiveQuota(project, r)
+		if !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, v)
+		}
+	}
+}
@@ -1,40 +1,438 @@
 package ccloud
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/domains"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/extensions/trusts"
+	osClient "github.com/gophercloud/utils/client"
 	"github.com/gophercloud/utils/openstack/clientconfig"
 	"github.com/sapcc/gophercloud-sapcc/clients"
 )
 
-func (c *Config) limesV1Client(region string) (*gophercloud.ServiceClient, error) {
-	return c.CommonServiceClientInit(clients.NewLimesV1, region, "resources")
+// authenticateWithOptions authenticates the provider client using the TOTP
+// passcode or the Keystone trust, which are not supported by auth.Config.
+func (c *Config) authenticateWithOptions(passcode, trustID string) error {
+	clientOpts := new(clientconfig.ClientOpts)
+	if c.Cloud != "" {
+		clientOpts.Cloud = c.Cloud
+		clientOpts.RegionName = c.Region
+	} else {
+		clientOpts.AuthInfo = &clientconfig.AuthInfo{
+			AuthURL:                     c.IdentityEndpoint,
+			ApplicationCredentialID:     c.ApplicationCredentialID,
+			ApplicationCredentialName:   c.ApplicationCredentialName,
+			ApplicationCredentialSecret: c.ApplicationCredentialSecret,
+			DefaultDomain:               c.DefaultDomain,
+			DomainID:                    c.DomainID,
+			DomainName:                  c.DomainName,
+			Password:                    c.Password,
+			ProjectDomainID:             c.ProjectDomainID,
+			ProjectDomainName:           c.ProjectDomainName,
+			ProjectID:                   c.TenantID,
+			ProjectName:                 c.TenantName,
+			Token:                       c.Token,
+			UserDomainID:                c.UserDomainID,
+			UserDomainName:              c.UserDomainName,
+			Username:                    c.Username,
+			UserID:                      c.UserID,
+		}
+	}
+
+	ao, err := clientconfig.AuthOptions(clientOpts)
+	if err != nil {
+		return err
+	}
+
+	if ao.ApplicationCredentialID != "" || ao.ApplicationCredentialName != "" {
+		return fmt.Errorf("The passcode and the trust_id cannot be used along with the application credential authentication")
+	}
+
+	if passcode != "" {
+		if ao.Password == "" || ao.TokenID != "" {
+			return fmt.Errorf("The passcode can be used only along with the password authentication")
+		}
+		ao.Passcode = passcode
+	}
+
+	ao.AllowReauth = c.AllowReauth
+	if passcode != "" && c.AllowReauth {
+		// the passcode expires shortly, the reauthentication would fail
+		log.Printf("[WARN] The reauthentication is disabled for the passcode authentication")
+		ao.AllowReauth = false
+	}
+
+	if trustID != "" {
+		if ao.TenantID != "" || ao.TenantName != "" || c.DomainID != "" || c.DomainName != "" {
+			return fmt.Errorf("The trust_id cannot be used along with the project or domain scope")
+		}
+
+		// the token is scoped to the trust only
+		ao.Scope = new(gophercloud.AuthScope)
+		opts := trusts.AuthOptsExt{AuthOptionsBuilder: ao, TrustID: trustID}
+		if err := openstack.AuthenticateV3(c.OsClient, opts, gophercloud.EndpointOpts{}); err != nil {
+			return err
+		}
+	} else if err := openstack.Authenticate(c.OsClient, *ao); err != nil {
+		return err
+	}
+
+	// the client is already authenticated, the delayed authentication
+	// would use the auth options without the passcode and the trust
+	c.DelayedAuth = false
+
+	return nil
 }
 
-func (c *Config) kubernikusV1Client(region string, isAdmin bool) (*kubernikus, error) {
-	if err := c.Authenticate(); err != nil {
+// appendCACertPEM adds the PEM encoded CA certificates to the certificates
+// trusted by the provider client, including the ones from the cacert_file.
+func (c *Config) appendCACertPEM(pem string) error {
+	rt, ok := c.OsClient.HTTPClient.Transport.(*osClient.RoundTripper)
+	if !ok {
+		return fmt.Errorf("Unexpected %T provider client transport", c.OsClient.HTTPClient.Transport)
+	}
+	transport, ok := rt.Rt.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("Unexpected %T provider client transport", rt.Rt)
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+
+	pool := transport.TLSClientConfig.RootCAs
+	if pool == nil {
+		var err error
+		if pool, err = x509.SystemCertPool(); err != nil {
+			log.Printf("[DEBUG] Failed to load the system CA certificates: %s", err)
+			pool = x509.NewCertPool()
+		}
+	}
+
+	if !pool.AppendCertsFromPEM([]byte(strings.TrimSpace(pem))) {
+		return fmt.Errorf("Error parsing CA Cert from the cacert_pem")
+	}
+	transport.TLSClientConfig.RootCAs = pool
+
+	return nil
+}
+
+func (c *Config) limesV1Client(region string) (*gophercloud.ServiceClient, error) {
+	if err := c.checkServiceAvailable("resources", region); err != nil {
 		return nil, err
 	}
 
+	client, err := c.CommonServiceClientInit(clients.NewLimesV1, region, "resources")
+	return c.setServiceToken(client), err
+}
+
+func (c *Config) kubernikusV1Client(region string, isAdmin bool) (*kubernikus, error) {
 	serviceType := "kubernikus"
 	if isAdmin {
 		serviceType = "kubernikus-kubernikus"
 	}
 
-	return newKubernikusV1(c, gophercloud.EndpointOpts{
+	if err := c.checkServiceAvailable(serviceType, region); err != nil {
+		return nil, err
+	}
+
+	client, err := newKubernikusV1(c, gophercloud.EndpointOpts{
 		Type:         serviceType,
 		Region:       c.DetermineRegion(region),
 		Availability: clientconfig.GetEndpointType(c.EndpointType),
 	})
+	return client, err
 }
 
 func (c *Config) arcV1Client(region string) (*gophercloud.ServiceClient, error) {
-	return c.CommonServiceClientInit(clients.NewArcV1, region, "arc")
+	if err := c.checkServiceAvailable("arc", region); err != nil {
+		return nil, err
+	}
+
+	client, err := c.CommonServiceClientInit(clients.NewArcV1, region, "arc")
+	return c.setServiceToken(client), err
 }
 
 func (c *Config) automationV1Client(region string) (*gophercloud.ServiceClient, error) {
-	return c.CommonServiceClientInit(clients.NewAutomationV1, region, "automation")
+	if err := c.checkServiceAvailable("automation", region); err != nil {
+		return nil, err
+	}
+
+	client, err := c.CommonServiceClientInit(clients.NewAutomationV1, region, "automation")
+	return c.setServiceToken(client), err
 }
 
 func (c *Config) billingClient(region string) (*gophercloud.ServiceClient, error) {
-	return c.CommonServiceClientInit(clients.NewBilling, region, "sapcc-billing")
+	if err := c.checkServiceAvailable("sapcc-billing", region); err != nil {
+		return nil, err
+	}
+
+	client, err := c.CommonServiceClientInit(clients.NewBilling, region, "sapcc-billing")
+	return c.setServiceToken(client), err
+}
+
+func (c *Config) IdentityV3Client(region string) (*gophercloud.ServiceClient, error) {
+	client, err := c.Config.IdentityV3Client(region)
+	return c.setServiceToken(client), err
+}
+
+func (c *Config) ComputeV2Client(region string) (*gophercloud.ServiceClient, error) {
+	client, err := c.Config.ComputeV2Client(region)
+	return c.setServiceToken(client), err
+}
+
+// limesIdentityClient returns the identity client, which is used to detect
+// the deleted projects, while waiting for them to appear in Limes. The nil
+// client is returned, when the identity service is not available.
+func (c *Config) limesIdentityClient(region string) *gophercloud.ServiceClient {
+	client, err := c.IdentityV3Client(region)
+	if err != nil {
+		log.Printf("[DEBUG] Error creating OpenStack identity client: %s", err)
+		return nil
+	}
+
+	return client
+}
+
+// domainName returns the Keystone domain name. The result is cached for the
+// provider lifetime, i.e. the domain is resolved once per Terraform run. The
+// failures are cached as the empty name, since the domain may be not readable
+// with the project scoped token at all.
+func (c *Config) domainName(client *gophercloud.ServiceClient, domainID string) string {
+	if v, ok := c.domainNames.Load(domainID); ok {
+		return v.(string)
+	}
+
+	var name string
+	if domain, err := domains.Get(client, domainID).Extract(); err == nil {
+		name = domain.Name
+	} else {
+		log.Printf("[DEBUG] Failed to get %s domain name: %s", domainID, err)
+	}
+	c.domainNames.Store(domainID, name)
+
+	return name
+}
+
+// errServiceNotAvailable is returned, when the service is missing in the
+// service catalog of the region.
+type errServiceNotAvailable struct {
+	service string
+	region  string
+	err     error
+}
+
+func (e errServiceNotAvailable) Error() string {
+	return fmt.Sprintf("The %q service is not available in the %q region: %s", e.service, e.region, e.err)
+}
+
+// probeServiceCatalog returns the endpoints of the ccloudServiceTypes, which
+// are available in the service catalog of the region, including the endpoint
+// overrides. The catalog is probed once per region, the result is cached for
+// the provider lifetime.
+func (c *Config) probeServiceCatalog(region string) (map[string]string, error) {
+	if err := c.Authenticate(); err != nil {
+		return nil, err
+	}
+
+	region = c.DetermineRegion(region)
+	if v, ok := c.serviceCatalogs.Load(region); ok {
+		return v.(map[string]string), nil
+	}
+
+	endpoints := make(map[string]string)
+	for _, service := range ccloudServiceTypes {
+		if v, ok := c.EndpointOverrides[strings.TrimSuffix(service, "-kubernikus")].(string); ok && v != "" {
+			endpoints[service] = v
+			continue
+		}
+		endpoint, err := c.OsClient.EndpointLocator(gophercloud.EndpointOpts{
+			Type:         service,
+			Region:       region,
+			Availability: clientconfig.GetEndpointType(c.EndpointType),
+		})
+		if err != nil {
+			log.Printf("[DEBUG] The %q service is not available in the %q region: %s", service, region, err)
+			continue
+		}
+		endpoints[service] = endpoint
+	}
+	c.serviceCatalogs.Store(region, endpoints)
+
+	return endpoints, nil
+}
+
+// checkServiceAvailable returns a clear error, when the service is missing in
+// the service catalog of the region.
+func (c *Config) checkServiceAvailable(service, region string) error {
+	endpoints, err := c.probeServiceCatalog(region)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := endpoints[service]; !ok {
+		return errServiceNotAvailable{service, c.DetermineRegion(region), &gophercloud.ErrEndpointNotFound{}}
+	}
+
+	return nil
+}
+
+const (
+	// maxBackoffDelay is the maximum time to wait before a 429 response is
+	// retried.
+	maxBackoffDelay = 60 * time.Second
+	// defaultMaxBackoffRetries is the amount of the 429 response retries,
+	// when the max_backoff_retries is not set.
+	defaultMaxBackoffRetries = 3
+)
+
+// retryBackoffFunc waits before a 429 (Too Many Requests) response is retried.
+// The delay is taken from the "Retry-After" header, which may contain either
+// seconds or an HTTP-date. When the header is missing or invalid, the delay
+// grows exponentially with every retry.
+func retryBackoffFunc(ctx context.Context, respErr *gophercloud.ErrUnexpectedResponseCode, e error, retries uint) error {
+	var sleep time.Duration
+
+	retryAfter := respErr.ResponseHeader.Get("Retry-After")
+	if v, err := strconv.ParseUint(retryAfter, 10, 32); err == nil {
+		sleep = time.Duration(v) * time.Second
+	} else if v, err := http.ParseTime(retryAfter); err == nil {
+		sleep = time.Until(v)
+	} else if retries < 16 {
+		sleep = time.Second << retries
+	} else {
+		sleep = maxBackoffDelay
+	}
+
+	if sleep > maxBackoffDelay {
+		sleep = maxBackoffDelay
+	} else if sleep < 0 {
+		sleep = 0
+	}
+
+	log.Printf("[DEBUG] Received %d response code for %s %s, retry %d, sleeping for %s", respErr.Actual, respErr.Method, respErr.URL, retries, sleep)
+
+	if ctx == nil {
+		time.Sleep(sleep)
+		return nil
+	}
+
+	select {
+	case <-time.After(sleep):
+	case <-ctx.Done():
+		log.Printf("[DEBUG] Sleeping aborted: %s", ctx.Err())
+		return e
+	}
+
+	return nil
+}
+
+// limitedRoundTripper limits the amount of concurrent HTTP requests.
+type limitedRoundTripper struct {
+	rt  http.RoundTripper
+	sem chan struct{}
+}
+
+func newLimitedRoundTripper(rt http.RoundTripper, limit int) *limitedRoundTripper {
+	return &limitedRoundTripper{
+		rt:  rt,
+		sem: make(chan struct{}, limit),
+	}
+}
+
+func (lrt *limitedRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	select {
+	case lrt.sem <- struct{}{}:
+	case <-request.Context().Done():
+		return nil, request.Context().Err()
+	}
+	defer func() { <-lrt.sem }()
+
+	return lrt.rt.RoundTrip(request)
+}
+
+// requestIDHeader is the header, which is used by the OpenStack services to
+// identify the request in the server logs.
+const requestIDHeader = "X-Openstack-Request-Id"
+
+// requestIDRoundTripper sets a generated request ID for every HTTP request
+// and logs it along with the request ID returned by the server. The request ID
+// is set on the request itself, like the headers set by the osClient
+// RoundTripper, so that its connection retries keep the same request ID.
+type requestIDRoundTripper struct {
+	rt     http.RoundTripper
+	prefix string
+}
+
+func newRequestIDRoundTripper(rt http.RoundTripper, prefix string) *requestIDRoundTripper {
+	return &requestIDRoundTripper{
+		rt:     rt,
+		prefix: prefix,
+	}
+}
+
+func (rrt *requestIDRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestID := request.Header.Get(requestIDHeader)
+	if requestID == "" {
+		var err error
+		if requestID, err = newRequestID(rrt.prefix); err != nil {
+			return nil, err
+		}
+		request.Header.Set(requestIDHeader, requestID)
+	}
+
+	response, err := rrt.rt.RoundTrip(request)
+	if err != nil {
+		log.Printf("[DEBUG] %s request ID %s failed: %s", request.Method, requestID, err)
+		return response, err
+	}
+
+	log.Printf("[DEBUG] %s %s request ID %s, response request ID %s", request.Method, request.URL, requestID, response.Header.Get(requestIDHeader))
+
+	return response, nil
+}
+
+// newRequestID returns a random UUID based request ID with the prefix, e.g.
+// "req-5b2a5b9c-4c1f-4d02-8a54-dd1e3b0d9d2b".
+func newRequestID(prefix string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Failed to generate the request ID: %s", err)
+	}
+	// UUID version 4, variant 1
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%s%x-%x-%x-%x-%x", prefix, b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// serviceTokenHeader is the header, which is used by the OpenStack services
+// to authorize the service-to-service requests.
+const serviceTokenHeader = "X-Service-Token"
+
+// setServiceToken sets the service token header for the requests of the
+// OpenStack service client. The header is not set in the shared provider HTTP
+// client, since it is used for the non OpenStack requests as well.
+func (c *Config) setServiceToken(client *gophercloud.ServiceClient) *gophercloud.ServiceClient {
+	if client == nil || c.ServiceToken == "" {
+		return client
+	}
+
+	if client.MoreHeaders == nil {
+		client.MoreHeaders = make(map[string]string)
+	}
+	client.MoreHeaders[serviceTokenHeader] = c.ServiceToken
+
+	return client
 }
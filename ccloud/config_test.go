@@ -0,0 +1,691 @@
+package ccloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/domains"
+	osClient "github.com/gophercloud/utils/client"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestRetryBackoffFunc(t *testing.T) {
+	cases := []struct {
+		name       string
+		retryAfter string
+	}{
+		{"seconds", "1"},
+		{"http-date", time.Now().Add(-time.Second).UTC().Format(http.TimeFormat)},
+		{"missing header", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					if c.retryAfter != "" {
+						w.Header().Set("Retry-After", c.retryAfter)
+					}
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := &gophercloud.ProviderClient{
+				HTTPClient:        *server.Client(),
+				MaxBackoffRetries: 1,
+				RetryBackoffFunc:  retryBackoffFunc,
+			}
+
+			_, err := client.Request("GET", server.URL, &gophercloud.RequestOpts{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if calls != 2 {
+				t.Fatalf("expected 2 requests, got %d", calls)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffFuncExhausted(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &gophercloud.ProviderClient{
+		HTTPClient:        *server.Client(),
+		MaxBackoffRetries: 2,
+		RetryBackoffFunc:  retryBackoffFunc,
+	}
+
+	_, err := client.Request("GET", server.URL, &gophercloud.RequestOpts{})
+	if _, ok := err.(gophercloud.ErrDefault429); !ok {
+		t.Fatalf("expected a 429 error, got %T: %v", err, err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 requests, got %d", calls)
+	}
+}
+
+func TestConfigDomainName(t *testing.T) {
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		if r.URL.Path != "/domains/d1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"domain":{"id":"d1","name":"Default"}}`))
+	}))
+	defer server.Close()
+
+	config := &Config{}
+	client := testServiceClient(server)
+	for i := 0; i < 2; i++ {
+		if v := config.domainName(client, "d1"); v != "Default" {
+			t.Errorf("expected \"Default\" domain name, got %q", v)
+		}
+		if v := config.domainName(client, "d2"); v != "" {
+			t.Errorf("expected an empty domain name, got %q", v)
+		}
+	}
+
+	for _, path := range []string{"/domains/d1", "/domains/d2"} {
+		if calls[path] != 1 {
+			t.Errorf("expected a single %s request, got %d", path, calls[path])
+		}
+	}
+}
+
+func TestLimitedRoundTripper(t *testing.T) {
+	const limit = 3
+
+	var mutex sync.Mutex
+	var running, max int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newLimitedRoundTripper(http.DefaultTransport, limit)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if max > limit {
+		t.Fatalf("expected at most %d concurrent requests, got %d", limit, max)
+	}
+	if max == 0 {
+		t.Fatal("expected the requests to reach the server")
+	}
+}
+
+func TestLimitedRoundTripperCanceled(t *testing.T) {
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+	}))
+	defer server.Close()
+	defer close(blocked)
+
+	client := &http.Client{Transport: newLimitedRoundTripper(http.DefaultTransport, 1)}
+	go client.Get(server.URL)
+	time.Sleep(20 * time.Millisecond)
+
+	// the second request waits for the free slot until it is canceled
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected the canceled request to fail")
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	re := regexp.MustCompile(`^req-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	ids := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id, err := newRequestID("req-")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !re.MatchString(id) {
+			t.Fatalf("unexpected %q request ID format", id)
+		}
+		if ids[id] {
+			t.Fatalf("duplicate %q request ID", id)
+		}
+		ids[id] = true
+	}
+
+	if id, _ := newRequestID(""); strings.HasPrefix(id, "req-") {
+		t.Errorf("unexpected %q request ID prefix", id)
+	}
+}
+
+func TestRequestIDRoundTripper(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(requestIDHeader))
+		w.Header().Set(requestIDHeader, "req-server")
+	}))
+	defer server.Close()
+
+	defer log.SetOutput(os.Stderr)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	client := &http.Client{Transport: newRequestIDRoundTripper(http.DefaultTransport, "tf-")}
+
+	// the generated request ID
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	// the request ID set by the caller is kept
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	req.Header.Set(requestIDHeader, "req-caller")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if len(received) != 2 || !strings.HasPrefix(received[0], "tf-") || received[1] != "req-caller" {
+		t.Fatalf("unexpected request IDs: %v", received)
+	}
+
+	logs := buf.String()
+	for _, v := range []string{
+		fmt.Sprintf("request ID %s, response request ID req-server", received[0]),
+		"request ID req-caller, response request ID req-server",
+	} {
+		if !strings.Contains(logs, v) {
+			t.Errorf("expected %q in the logs, got %q", v, logs)
+		}
+	}
+}
+
+// failingRoundTripper fails the first requests with a connection error.
+type failingRoundTripper struct {
+	rt       http.RoundTripper
+	failures int
+}
+
+func (frt *failingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	if frt.failures > 0 {
+		frt.failures--
+		return nil, fmt.Errorf("connection refused")
+	}
+	return frt.rt.RoundTrip(request)
+}
+
+func TestRequestIDRoundTripperRetries(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(requestIDHeader))
+	}))
+	defer server.Close()
+
+	defer log.SetOutput(os.Stderr)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	client := &http.Client{Transport: &osClient.RoundTripper{
+		Rt:         newRequestIDRoundTripper(&failingRoundTripper{rt: http.DefaultTransport, failures: 2}, "tf-"),
+		MaxRetries: 2,
+	}}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if len(received) != 1 || !strings.HasPrefix(received[0], "tf-") {
+		t.Fatalf("unexpected request IDs: %v", received)
+	}
+
+	// the failed attempts are logged with the same request ID
+	if v := strings.Count(buf.String(), fmt.Sprintf("request ID %s failed", received[0])); v != 2 {
+		t.Errorf("expected 2 failed attempts with the %s request ID, got %d", received[0], v)
+	}
+}
+
+func TestConfigAuthenticateWithPasscode(t *testing.T) {
+	var methods []string
+	var passcode string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v3/auth/tokens" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body struct {
+			Auth struct {
+				Identity struct {
+					Methods []string `json:"methods"`
+					TOTP    struct {
+						User struct {
+							Passcode string `json:"passcode"`
+						} `json:"user"`
+					} `json:"totp"`
+				} `json:"identity"`
+			} `json:"auth"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode the auth request: %s", err)
+		}
+		methods = body.Auth.Identity.Methods
+		passcode = body.Auth.Identity.TOTP.User.Passcode
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Subject-Token", "token")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token":{"catalog":[]}}`))
+	}))
+	defer server.Close()
+
+	config := &Config{}
+	config.IdentityEndpoint = server.URL + "/v3/"
+	config.Username = "user"
+	config.Password = "secret"
+	config.UserDomainName = "Default"
+	config.DelayedAuth = true
+	config.AllowReauth = true
+	client, err := openstack.NewClient(config.IdentityEndpoint)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	config.OsClient = client
+
+	if err := config.authenticateWithOptions("123456", ""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sort.Strings(methods)
+	if expected := []string{"password", "totp"}; !reflect.DeepEqual(methods, expected) {
+		t.Errorf("expected %v auth methods, got %v", expected, methods)
+	}
+	if passcode != "123456" {
+		t.Errorf("expected the \"123456\" passcode, got %q", passcode)
+	}
+	if config.DelayedAuth {
+		t.Error("expected the delayed authentication to be disabled")
+	}
+	if config.OsClient.ReauthFunc != nil {
+		t.Error("expected the reauthentication to be disabled")
+	}
+}
+
+func TestConfigAuthenticateWithPasscodeErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		config func(*Config)
+	}{
+		{"token", func(c *Config) { c.Token = "token" }},
+		{"application credential", func(c *Config) {
+			c.ApplicationCredentialID = "id"
+			c.ApplicationCredentialSecret = "secret"
+		}},
+	}
+
+	for _, c := range cases {
+		config := &Config{}
+		config.IdentityEndpoint = "http://127.0.0.1:0/v3/"
+		c.config(config)
+		config.OsClient = &gophercloud.ProviderClient{}
+		if err := config.authenticateWithOptions("123456", ""); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
+
+func TestConfigAuthenticateWithTrust(t *testing.T) {
+	var methods []string
+	var scope map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Auth struct {
+				Identity struct {
+					Methods []string `json:"methods"`
+				} `json:"identity"`
+				Scope map[string]interface{} `json:"scope"`
+			} `json:"auth"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode the auth request: %s", err)
+		}
+		methods = body.Auth.Identity.Methods
+		scope = body.Auth.Scope
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Subject-Token", "token")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token":{"catalog":[]}}`))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name   string
+		config func(*Config)
+		err    bool
+	}{
+		{"password", func(c *Config) {}, false},
+		{"project scope", func(c *Config) { c.TenantName = "project" }, true},
+		{"domain scope", func(c *Config) { c.DomainName = "domain" }, true},
+		{"application credential", func(c *Config) {
+			c.ApplicationCredentialID = "id"
+			c.ApplicationCredentialSecret = "secret"
+		}, true},
+	}
+
+	for _, c := range cases {
+		methods, scope = nil, nil
+		config := &Config{}
+		config.IdentityEndpoint = server.URL + "/v3/"
+		config.Username = "user"
+		config.Password = "secret"
+		config.UserDomainName = "Default"
+		config.DelayedAuth = true
+		c.config(config)
+		client, err := openstack.NewClient(config.IdentityEndpoint)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+		config.OsClient = client
+
+		err = config.authenticateWithOptions("", "trust1")
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			if methods != nil {
+				t.Errorf("%s: expected no auth request", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+		if expected := []string{"password"}; !reflect.DeepEqual(methods, expected) {
+			t.Errorf("%s: expected %v auth methods, got %v", c.name, expected, methods)
+		}
+		// the token is scoped to the trust only
+		expected := map[string]interface{}{"OS-TRUST:trust": map[string]interface{}{"id": "trust1"}}
+		if !reflect.DeepEqual(scope, expected) {
+			t.Errorf("%s: expected %v scope, got %v", c.name, expected, scope)
+		}
+		if config.DelayedAuth {
+			t.Errorf("%s: expected the delayed authentication to be disabled", c.name)
+		}
+	}
+}
+
+func TestConfigAppendCACertPEM(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	cases := []struct {
+		name string
+		pem  string
+		err  bool
+	}{
+		{"no pem", "", false},
+		{"invalid pem", "invalid", true},
+		{"server ca", "\n" + string(cert) + "\n", false},
+	}
+
+	for _, c := range cases {
+		config := &Config{}
+		config.OsClient = &gophercloud.ProviderClient{
+			HTTPClient: http.Client{Transport: &osClient.RoundTripper{Rt: &http.Transport{}}},
+		}
+
+		if c.pem != "" {
+			err := config.appendCACertPEM(c.pem)
+			if c.err {
+				if err == nil {
+					t.Errorf("%s: expected an error", c.name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s", c.name, err)
+			}
+		}
+
+		// the connection is trusted only with the server CA
+		resp, err := config.OsClient.HTTPClient.Get(server.URL)
+		if c.pem == "" {
+			if err == nil || !strings.Contains(err.Error(), "certificate") {
+				t.Errorf("%s: expected the certificate error, got %v", c.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+		resp.Body.Close()
+	}
+
+	// the cacert_pem is combined with the cacert_file certificates
+	other := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(other.Certificate())
+	config := &Config{}
+	config.OsClient = &gophercloud.ProviderClient{
+		HTTPClient: http.Client{Transport: &osClient.RoundTripper{Rt: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}},
+	}
+	if err := config.appendCACertPEM(string(cert)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, url := range []string{server.URL, other.URL} {
+		resp, err := config.OsClient.HTTPClient.Get(url)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestConfigServiceToken(t *testing.T) {
+	headers := make(map[string]http.Header)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers[r.URL.Path] = r.Header
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"domain":{"id":"d1","name":"Default"}}`))
+	}))
+	defer server.Close()
+
+	config := &Config{ServiceToken: "service-token"}
+	config.OsClient = &gophercloud.ProviderClient{
+		HTTPClient: *server.Client(),
+		EndpointLocator: func(gophercloud.EndpointOpts) (string, error) {
+			return server.URL + "/", nil
+		},
+	}
+	config.OsClient.SetToken("user-token")
+
+	identity, err := config.IdentityV3Client("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := domains.Get(identity, "d1").Extract(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the shared provider HTTP client doesn't send the service token
+	resp, err := config.OsClient.HTTPClient.Get(server.URL + "/webhook")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	h := headers["/v3/domains/d1"]
+	if h == nil {
+		t.Fatalf("expected the identity request, got %v", headers)
+	}
+	if v := h.Get("X-Auth-Token"); v != "user-token" {
+		t.Errorf("expected the \"user-token\" user token, got %q", v)
+	}
+	if v := h.Get(serviceTokenHeader); v != "service-token" {
+		t.Errorf("expected the \"service-token\" service token, got %q", v)
+	}
+	if v := headers["/webhook"].Get(serviceTokenHeader); v != "" {
+		t.Errorf("unexpected %q service token in the non OpenStack request", v)
+	}
+}
+
+func TestConfigEndpointType(t *testing.T) {
+	cases := []struct {
+		endpointType string
+		expected     gophercloud.Availability
+	}{
+		{"", gophercloud.AvailabilityPublic},
+		{"public", gophercloud.AvailabilityPublic},
+		{"internal", gophercloud.AvailabilityInternal},
+		{"internalURL", gophercloud.AvailabilityInternal},
+		{"admin", gophercloud.AvailabilityAdmin},
+		{"adminURL", gophercloud.AvailabilityAdmin},
+	}
+
+	for _, c := range cases {
+		availability := make(map[string]gophercloud.Availability)
+		config := &Config{}
+		config.EndpointType = c.endpointType
+		config.OsClient = &gophercloud.ProviderClient{
+			EndpointLocator: func(eo gophercloud.EndpointOpts) (string, error) {
+				if v, ok := availability[eo.Type]; ok && v != eo.Availability {
+					t.Errorf("%q: inconsistent %s availability: %s and %s", c.endpointType, eo.Type, v, eo.Availability)
+				}
+				availability[eo.Type] = eo.Availability
+				return "http://127.0.0.1/", nil
+			},
+		}
+
+		clients := map[string]func() error{
+			"resources":     func() error { _, err := config.limesV1Client(""); return err },
+			"arc":           func() error { _, err := config.arcV1Client(""); return err },
+			"automation":    func() error { _, err := config.automationV1Client(""); return err },
+			"sapcc-billing": func() error { _, err := config.billingClient(""); return err },
+			"kubernikus":    func() error { _, err := config.kubernikusV1Client("", false); return err },
+		}
+		for service, client := range clients {
+			if err := client(); err != nil {
+				t.Fatalf("%q: unexpected %s error: %s", c.endpointType, service, err)
+			}
+			if v := availability[service]; v != c.expected {
+				t.Errorf("%q: expected %q %s availability, got %q", c.endpointType, c.expected, service, v)
+			}
+		}
+	}
+
+	// the unknown endpoint types are rejected
+	p := Provider().(*schema.Provider)
+	if _, errs := p.Schema["endpoint_type"].ValidateFunc("private", "endpoint_type"); len(errs) == 0 {
+		t.Error("expected the unknown endpoint_type to be rejected")
+	}
+}
+
+func TestConfigServiceNotAvailable(t *testing.T) {
+	probes := make(map[string]int)
+	config := &Config{}
+	config.Region = "r1"
+	config.OsClient = &gophercloud.ProviderClient{
+		EndpointLocator: func(eo gophercloud.EndpointOpts) (string, error) {
+			probes[eo.Region]++
+			if eo.Type == "sapcc-billing" && eo.Region == "r2" {
+				return "", &gophercloud.ErrEndpointNotFound{}
+			}
+			return "http://127.0.0.1/", nil
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceCCloudBillingProjectMasterdata().Schema, map[string]interface{}{
+		"region":     "r2",
+		"project_id": "p1",
+	})
+	d.SetId("p1")
+
+	for i := 0; i < 2; i++ {
+		err := resourceCCloudBillingProjectMasterdataRead(d, config)
+		if err == nil || !strings.Contains(err.Error(), `The "sapcc-billing" service is not available in the "r2" region`) {
+			t.Fatalf("expected the service not available error, got %v", err)
+		}
+	}
+
+	// the catalog is probed once per region, the client initialization
+	// additionally locates its own endpoint
+	if probes["r2"] != len(ccloudServiceTypes) {
+		t.Errorf("expected %d r2 probes, got %d", len(ccloudServiceTypes), probes["r2"])
+	}
+	for i := 1; i <= 2; i++ {
+		if _, err := config.billingClient(""); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if probes["r1"] != len(ccloudServiceTypes)+i {
+			t.Errorf("expected %d r1 probes, got %d", len(ccloudServiceTypes)+i, probes["r1"])
+		}
+	}
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 
+	"github.com/gophercloud/gophercloud/pagination"
 	"github.com/sapcc/gophercloud-sapcc/arc/v1/agents"
 )
 
@@ -28,6 +30,24 @@ func dataSourceCCloudArcAgentIDsV1() *schema.Resource {
 				Optional: true,
 			},
 
+			"facts_filter": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateArcAgentV1FactsFilter,
+			},
+
+			"page_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
 			// computed attributes
 			"ids": {
 				Type:     schema.TypeList,
@@ -47,18 +67,56 @@ func dataSourceCCloudArcAgentIDsV1Read(d *schema.ResourceData, meta interface{})
 
 	filter := d.Get("filter").(string)
 
-	listOpts := agents.ListOpts{Filter: filter}
+	var factsFilter *arcAgentV1FactsFilter
+	if v := d.Get("facts_filter").(string); v != "" {
+		factsFilter, err = arcAgentV1ParseFactsFilter(v)
+		if err != nil {
+			return err
+		}
+		if f := factsFilter.serverFilter(); f != "" {
+			if filter != "" {
+				filter = fmt.Sprintf("(%s) AND %s", filter, f)
+			} else {
+				filter = f
+			}
+		}
+	}
+
+	listOpts := agents.ListOpts{
+		Filter:  filter,
+		PerPage: d.Get("page_size").(int),
+	}
+	limit := d.Get("limit").(int)
 
 	log.Printf("[DEBUG] ccloud_arc_agent_ids_v1 list options: %#v", listOpts)
 
-	allPages, err := agents.List(arcClient, listOpts).AllPages()
+	var allAgents []agents.Agent
+	err = listPages(agents.List(arcClient, listOpts), limit, func(page pagination.Page) (int, error) {
+		v, err := agents.ExtractAgents(page)
+		if err != nil {
+			return 0, fmt.Errorf("Unable to retrieve ccloud_arc_agent_ids_v1: %s", err)
+		}
+		if factsFilter != nil {
+			if err := arcAgentV1GetFacts(arcClient, v, config.MaxParallelRequests); err != nil {
+				return 0, err
+			}
+		}
+		var n int
+		for _, agent := range v {
+			if factsFilter != nil && !factsFilter.match(agent.Facts) {
+				continue
+			}
+			allAgents = append(allAgents, agent)
+			n++
+		}
+		return n, nil
+	})
 	if err != nil {
 		return fmt.Errorf("Unable to list ccloud_arc_agent_ids_v1: %s", err)
 	}
 
-	allAgents, err := agents.ExtractAgents(allPages)
-	if err != nil {
-		return fmt.Errorf("Unable to retrieve ccloud_arc_agent_ids_v1: %s", err)
+	if limit > 0 && len(allAgents) > limit {
+		allAgents = allAgents[:limit]
 	}
 
 	var agentIDs []string
@@ -57,6 +57,18 @@ func dataSourceCCloudArcJobIDsV1() *schema.Resource {
 				}, false),
 			},
 
+			"page_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
 			"ids": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -73,7 +85,7 @@ func dataSourceCCloudArcJobIDsV1Read(d *schema.ResourceData, meta interface{}) e
 		return fmt.Errorf("Error creating OpenStack Arc client: %s", err)
 	}
 
-	jobs, err := arcCCloudArcJobV1Filter(d, arcClient, "ccloud_arc_job_ids_v1")
+	jobs, err := arcCCloudArcJobV1Filter(d, arcClient, "ccloud_arc_job_ids_v1", d.Get("page_size").(int), d.Get("limit").(int))
 	if err != nil {
 		return err
 	}
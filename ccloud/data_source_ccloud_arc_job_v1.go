@@ -286,7 +286,7 @@ func dataSourceCCloudArcJobV1Read(d *schema.ResourceData, meta interface{}) erro
 		}
 	} else {
 		// filter arc jobs by parameters
-		jobs, err := arcCCloudArcJobV1Filter(d, arcClient, "ccloud_arc_job_v1")
+		jobs, err := arcCCloudArcJobV1Filter(d, arcClient, "ccloud_arc_job_v1", 0, 0)
 		if err != nil {
 			return err
 		}
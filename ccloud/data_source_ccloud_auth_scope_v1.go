@@ -0,0 +1,172 @@
+package ccloud
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// authScopeResult is implemented by the Identity v3 token create and get
+// results.
+type authScopeResult interface {
+	ExtractToken() (*tokens.Token, error)
+	ExtractUser() (*tokens.User, error)
+	ExtractProject() (*tokens.Project, error)
+	ExtractDomain() (*tokens.Domain, error)
+	ExtractRoles() ([]tokens.Role, error)
+}
+
+func dataSourceCCloudAuthScopeV1() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCCloudAuthScopeV1Read,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			// computed attributes
+			"user_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"user_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"user_domain_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"user_domain_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"project_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"project_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"project_domain_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"project_domain_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"domain_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"domain_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"roles": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"expires_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceCCloudAuthScopeV1Read(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+	if err := config.Authenticate(); err != nil {
+		return err
+	}
+
+	result, ok := config.OsClient.GetAuthResult().(authScopeResult)
+	if !ok {
+		return fmt.Errorf("Error getting the auth scope: an Identity v3 token is required")
+	}
+
+	token, err := result.ExtractToken()
+	if err != nil {
+		return fmt.Errorf("Error extracting the token: %s", err)
+	}
+
+	user, err := result.ExtractUser()
+	if err != nil {
+		return fmt.Errorf("Error extracting the token user: %s", err)
+	}
+
+	project, err := result.ExtractProject()
+	if err != nil {
+		return fmt.Errorf("Error extracting the token project: %s", err)
+	}
+
+	domain, err := result.ExtractDomain()
+	if err != nil {
+		return fmt.Errorf("Error extracting the token domain: %s", err)
+	}
+
+	roles, err := result.ExtractRoles()
+	if err != nil {
+		return fmt.Errorf("Error extracting the token roles: %s", err)
+	}
+
+	log.Printf("[DEBUG] Retrieved ccloud_auth_scope_v1: user %+v, project %+v, domain %+v, roles %+v", user, project, domain, roles)
+
+	var roleNames []string
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+	}
+	sort.Strings(roleNames)
+
+	if user == nil {
+		return fmt.Errorf("Error getting the auth scope: the token has no user")
+	}
+
+	id := user.ID
+	d.Set("user_id", user.ID)
+	d.Set("user_name", user.Name)
+	d.Set("user_domain_id", user.Domain.ID)
+	d.Set("user_domain_name", user.Domain.Name)
+	if project != nil {
+		id += "/" + project.ID
+		d.Set("project_id", project.ID)
+		d.Set("project_name", project.Name)
+		d.Set("project_domain_id", project.Domain.ID)
+		d.Set("project_domain_name", project.Domain.Name)
+	}
+	if domain != nil {
+		id += "/" + domain.ID
+		d.Set("domain_id", domain.ID)
+		d.Set("domain_name", domain.Name)
+	}
+	d.SetId(id)
+	d.Set("roles", roleNames)
+	d.Set("expires_at", token.ExpiresAt.Format(time.RFC3339))
+
+	d.Set("region", GetRegion(d, config))
+
+	return nil
+}
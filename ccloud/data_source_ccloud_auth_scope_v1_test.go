@@ -0,0 +1,97 @@
+package ccloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestDataSourceCCloudAuthScopeV1Read(t *testing.T) {
+	const user = `"user":{"id":"u1","name":"user","domain":{"id":"ud1","name":"user-domain"}},` +
+		`"roles":[{"id":"r2","name":"member"},{"id":"r1","name":"admin"}],` +
+		`"expires_at":"2026-10-14T12:00:00.000000Z"`
+
+	cases := []struct {
+		name     string
+		token    string
+		id       string
+		expected map[string]interface{}
+	}{
+		{
+			"project scope",
+			`{"token":{` + user + `,"project":{"id":"p1","name":"project","domain":{"id":"d1","name":"domain"}}}}`,
+			"u1/p1",
+			map[string]interface{}{
+				"user_id":             "u1",
+				"user_name":           "user",
+				"user_domain_id":      "ud1",
+				"user_domain_name":    "user-domain",
+				"project_id":          "p1",
+				"project_name":        "project",
+				"project_domain_id":   "d1",
+				"project_domain_name": "domain",
+				"domain_id":           "",
+				"domain_name":         "",
+				"expires_at":          "2026-10-14T12:00:00Z",
+			},
+		},
+		{
+			"domain scope",
+			`{"token":{` + user + `,"domain":{"id":"d1","name":"domain"}}}`,
+			"u1/d1",
+			map[string]interface{}{
+				"user_id":             "u1",
+				"user_name":           "user",
+				"user_domain_id":      "ud1",
+				"user_domain_name":    "user-domain",
+				"project_id":          "",
+				"project_name":        "",
+				"project_domain_id":   "",
+				"project_domain_name": "",
+				"domain_id":           "d1",
+				"domain_name":         "domain",
+				"expires_at":          "2026-10-14T12:00:00Z",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Subject-Token", "token")
+			w.Write([]byte(c.token))
+		}))
+
+		config := testConfig(server)
+		result := tokens.Get(testServiceClient(server), "token")
+		if result.Err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, result.Err)
+		}
+		if err := config.OsClient.SetTokenAndAuthResult(result); err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+
+		d := schema.TestResourceDataRaw(t, dataSourceCCloudAuthScopeV1().Schema, map[string]interface{}{})
+		err := dataSourceCCloudAuthScopeV1Read(d, config)
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+
+		if d.Id() != c.id {
+			t.Errorf("%s: expected %q ID, got %q", c.name, c.id, d.Id())
+		}
+		for k, expected := range c.expected {
+			if v := d.Get(k); v != expected {
+				t.Errorf("%s: expected %q %s, got %q", c.name, expected, k, v)
+			}
+		}
+		if v := d.Get("roles"); !reflect.DeepEqual(v, []interface{}{"admin", "member"}) {
+			t.Errorf("%s: expected the sorted roles, got %v", c.name, v)
+		}
+	}
+}
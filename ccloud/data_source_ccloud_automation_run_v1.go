@@ -0,0 +1,165 @@
+package ccloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+
+	"github.com/sapcc/gophercloud-sapcc/automation/v1/runs"
+)
+
+func dataSourceCCloudAutomationRunV1() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCCloudAutomationRunV1Read,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"run_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"log_max_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      65536,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+
+			// Computed
+			"automation_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"automation_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"selector": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"repository_revision": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"automation_attributes": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"log": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"jobs": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"project_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"updated_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"owner": {
+				Type:     schema.TypeList,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"domain_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"domain_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceCCloudAutomationRunV1Read(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+	automationClient, err := config.automationV1Client(GetRegion(d, config))
+	if err != nil {
+		return fmt.Errorf("Error creating OpenStack Automation client: %s", err)
+	}
+
+	runID := d.Get("run_id").(string)
+	run, err := runs.Get(automationClient, runID).Extract()
+	if err != nil {
+		return fmt.Errorf("Unable to retrieve %s ccloud_automation_run_v1: %s", runID, err)
+	}
+
+	log.Printf("[DEBUG] Retrieved %s ccloud_automation_run_v1: %+v", run.ID, run)
+	d.SetId(run.ID)
+
+	d.Set("automation_id", run.AutomationID)
+	d.Set("automation_name", run.AutomationName)
+	d.Set("selector", run.Selector)
+	d.Set("repository_revision", run.RepositoryRevision)
+
+	automationAttributes, err := json.Marshal(run.AutomationAttributes)
+	if err != nil {
+		log.Printf("[DEBUG] dataSourceCCloudAutomationRunV1Read: Cannot marshal run.AutomationAttributes: %s", err)
+	}
+	d.Set("automation_attributes", string(automationAttributes))
+
+	d.Set("state", run.State)
+	d.Set("created_at", run.CreatedAt.Format(time.RFC3339))
+	d.Set("updated_at", run.UpdatedAt.Format(time.RFC3339))
+	d.Set("log", truncateLog(run.Log, d.Get("log_max_size").(int)))
+	d.Set("jobs", run.Jobs)
+	d.Set("owner", flattenAutomationiOwnerV1(run.Owner))
+	d.Set("project_id", run.ProjectID)
+
+	d.Set("region", GetRegion(d, config))
+
+	return nil
+}
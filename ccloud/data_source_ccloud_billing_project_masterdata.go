@@ -176,6 +176,16 @@ func dataSourceCCloudBillingProjectMasterdata() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+
+			"project_region": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"masterdata_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -245,6 +255,8 @@ func dataSourceCCloudBillingProjectMasterdataRead(d *schema.ResourceData, meta i
 	d.Set("is_complete", project.IsComplete)
 	d.Set("missing_attributes", project.MissingAttributes)
 	d.Set("collector", project.Collector)
+	d.Set("project_region", project.Region)
+	d.Set("masterdata_json", billingProjectMasterdataJSON(project))
 
 	d.Set("region", GetRegion(d, config))
 
@@ -0,0 +1,67 @@
+package ccloud
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// ccloudServiceTypes contains the service catalog types used by the provider.
+var ccloudServiceTypes = []string{
+	"arc",
+	"automation",
+	"kubernikus",
+	"kubernikus-kubernikus",
+	"resources",
+	"sapcc-billing",
+}
+
+func dataSourceCCloudEndpointsV1() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCCloudEndpointsV1Read,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			// computed attributes
+			"endpoints": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceCCloudEndpointsV1Read(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+	region := GetRegion(d, config)
+	endpoints, err := config.probeServiceCatalog(region)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for service, endpoint := range endpoints {
+		ids = append(ids, fmt.Sprintf("%s=%s", service, endpoint))
+	}
+	sort.Strings(ids)
+
+	log.Printf("[DEBUG] Retrieved ccloud_endpoints_v1: %+v", endpoints)
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(ids, ""))))
+	d.Set("endpoints", endpoints)
+
+	d.Set("region", region)
+
+	return nil
+}
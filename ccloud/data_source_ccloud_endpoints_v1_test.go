@@ -0,0 +1,105 @@
+package ccloud
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestDataSourceCCloudEndpointsV1Read(t *testing.T) {
+	endpoint := func(region, iface, url string) tokens.Endpoint {
+		return tokens.Endpoint{Region: region, RegionID: region, Interface: iface, URL: url}
+	}
+	catalog := &tokens.ServiceCatalog{
+		Entries: []tokens.CatalogEntry{
+			{Type: "resources", Endpoints: []tokens.Endpoint{
+				endpoint("r1", "public", "https://limes.r1/"),
+				endpoint("r1", "internal", "http://limes.r1.internal/"),
+				endpoint("r2", "public", "https://limes.r2/"),
+			}},
+			{Type: "sapcc-billing", Endpoints: []tokens.Endpoint{
+				endpoint("r1", "public", "https://billing.r1/"),
+			}},
+			{Type: "arc", Endpoints: []tokens.Endpoint{
+				endpoint("r1", "public", "https://arc.r1/"),
+			}},
+			{Type: "compute", Endpoints: []tokens.Endpoint{
+				endpoint("r1", "public", "https://nova.r1/"),
+			}},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		region       string
+		endpointType string
+		overrides    map[string]interface{}
+		expected     map[string]interface{}
+	}{
+		{
+			"public interface",
+			"r1",
+			"public",
+			nil,
+			map[string]interface{}{
+				"resources":     "https://limes.r1/",
+				"sapcc-billing": "https://billing.r1/",
+				"arc":           "https://arc.r1/",
+			},
+		},
+		{
+			"internal interface",
+			"r1",
+			"internal",
+			nil,
+			map[string]interface{}{
+				"resources": "http://limes.r1.internal/",
+			},
+		},
+		{
+			"other region",
+			"r2",
+			"public",
+			nil,
+			map[string]interface{}{
+				"resources": "https://limes.r2/",
+			},
+		},
+		{
+			"endpoint override",
+			"r2",
+			"public",
+			map[string]interface{}{"arc": "https://arc.override/"},
+			map[string]interface{}{
+				"resources": "https://limes.r2/",
+				"arc":       "https://arc.override/",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		config := &Config{}
+		config.Region = "r1"
+		config.EndpointType = c.endpointType
+		config.EndpointOverrides = c.overrides
+		config.OsClient = &gophercloud.ProviderClient{
+			EndpointLocator: func(eo gophercloud.EndpointOpts) (string, error) {
+				return openstack.V3EndpointURL(catalog, eo)
+			},
+		}
+
+		d := schema.TestResourceDataRaw(t, dataSourceCCloudEndpointsV1().Schema, map[string]interface{}{
+			"region": c.region,
+		})
+		if err := dataSourceCCloudEndpointsV1Read(d, config); err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+		if v := d.Get("endpoints"); !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: expected %v endpoints, got %v", c.name, c.expected, v)
+		}
+	}
+}
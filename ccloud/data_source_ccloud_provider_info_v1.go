@@ -0,0 +1,57 @@
+package ccloud
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceCCloudProviderInfoV1() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCCloudProviderInfoV1Read,
+
+		Schema: map[string]*schema.Schema{
+			// computed attributes
+			"version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"commit": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"terraform_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"sdk_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"features": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceCCloudProviderInfoV1Read(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+
+	log.Printf("[DEBUG] Provider version %s, commit %s, features: %v", ProviderVersion, ProviderCommit, config.EnabledFeatures)
+
+	d.SetId(ProviderVersion)
+	d.Set("version", ProviderVersion)
+	d.Set("commit", ProviderCommit)
+	d.Set("terraform_version", config.TerraformVersion)
+	d.Set("sdk_version", config.SDKVersion)
+	d.Set("features", config.EnabledFeatures)
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package ccloud
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestDataSourceCCloudProviderInfoV1Read(t *testing.T) {
+	config := &Config{
+		EnabledFeatures: []string{"allow_reauth", "max_retries"},
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceCCloudProviderInfoV1().Schema, map[string]interface{}{})
+	if err := dataSourceCCloudProviderInfoV1Read(d, config); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if v := d.Get("version").(string); v == "" {
+		t.Errorf("expected a non-empty version")
+	}
+	if d.Id() != ProviderVersion {
+		t.Errorf("expected %q ID, got %q", ProviderVersion, d.Id())
+	}
+
+	expected := []interface{}{"allow_reauth", "max_retries"}
+	if v := d.Get("features").([]interface{}); !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v features, got %v", expected, v)
+	}
+}
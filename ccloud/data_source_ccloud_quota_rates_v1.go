@@ -0,0 +1,153 @@
+package ccloud
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sapcc/gophercloud-sapcc/resources/v1/projects"
+)
+
+func dataSourceCCloudQuotaRatesV1() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCCloudQuotaRatesV1Read,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"domain_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"project_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"service": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"rates": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"service": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"unit": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"limit": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"window": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"default_limit": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"default_window": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"usage": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// limesProjectRatesGetOpts requests only the rate limits of the project report.
+type limesProjectRatesGetOpts struct {
+	Service string
+}
+
+func (opts limesProjectRatesGetOpts) ToProjectGetParams() (map[string]string, string, error) {
+	q := "?rates=only"
+	if opts.Service != "" {
+		q += "&service=" + url.QueryEscape(opts.Service)
+	}
+	return nil, q, nil
+}
+
+func dataSourceCCloudQuotaRatesV1Read(d *schema.ResourceData, meta interface{}) error {
+	domainID := d.Get("domain_id").(string)
+	projectID := d.Get("project_id").(string)
+
+	config := meta.(*Config)
+	client, err := config.limesV1Client(GetRegion(d, config))
+	if err != nil {
+		return fmt.Errorf("Error creating OpenStack limes client: %s", err)
+	}
+
+	opts := limesProjectRatesGetOpts{Service: d.Get("service").(string)}
+	quota, err := projects.Get(client, domainID, projectID, opts).Extract()
+	if err != nil {
+		return fmt.Errorf("Error getting Limes project rates: %s", err)
+	}
+
+	log.Printf("[DEBUG] Retrieved %s/%s ccloud_quota_rates_v1: %+v", domainID, projectID, *quota)
+
+	var rates []map[string]interface{}
+	for service, srv := range quota.Services {
+		for name, rate := range srv.Rates {
+			v := map[string]interface{}{
+				"service":       service,
+				"name":          name,
+				"unit":          string(rate.Unit),
+				"limit":         int(rate.Limit),
+				"default_limit": int(rate.DefaultLimit),
+				"usage":         rate.UsageAsBigint,
+			}
+			if rate.Window != nil {
+				v["window"] = rate.Window.String()
+			}
+			if rate.DefaultWindow != nil {
+				v["default_window"] = rate.DefaultWindow.String()
+			}
+			rates = append(rates, v)
+		}
+	}
+
+	// keep the order stable to avoid diffs
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i]["service"] != rates[j]["service"] {
+			return rates[i]["service"].(string) < rates[j]["service"].(string)
+		}
+		return rates[i]["name"].(string) < rates[j]["name"].(string)
+	})
+
+	d.SetId(projectID)
+	d.Set("rates", rates)
+
+	d.Set("region", GetRegion(d, config))
+
+	return nil
+}
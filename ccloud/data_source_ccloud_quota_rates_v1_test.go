@@ -0,0 +1,67 @@
+package ccloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestDataSourceCCloudQuotaRatesV1Read(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if v := r.URL.RawQuery; v != "rates=only&service=object-store" {
+			t.Errorf("unexpected %q query", v)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","services":[` +
+			`{"type":"object-store","area":"storage","rates":[` +
+			`{"name":"object/create","limit":100,"window":"1m","default_limit":50,"default_window":"1s","usage_as_bigint":"42"},` +
+			`{"name":"account/update","unit":"B","default_limit":10,"default_window":"1h"}]}]}}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceCCloudQuotaRatesV1().Schema, map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+		"service":    "object-store",
+	})
+	if err := dataSourceCCloudQuotaRatesV1Read(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the rates are sorted by the service and the name
+	expected := []interface{}{
+		map[string]interface{}{
+			"service":        "object-store",
+			"name":           "account/update",
+			"unit":           "B",
+			"limit":          0,
+			"window":         "",
+			"default_limit":  10,
+			"default_window": "1h",
+			"usage":          "",
+		},
+		map[string]interface{}{
+			"service":        "object-store",
+			"name":           "object/create",
+			"unit":           "",
+			"limit":          100,
+			"window":         "1m",
+			"default_limit":  50,
+			"default_window": "1s",
+			"usage":          "42",
+		},
+	}
+	if v := d.Get("rates"); !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v rates, got %v", expected, v)
+	}
+	if d.Id() != "p1" {
+		t.Errorf("expected \"p1\" ID, got %q", d.Id())
+	}
+}
@@ -0,0 +1,103 @@
+package ccloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sapcc/gophercloud-sapcc/resources/v1/projects"
+)
+
+func dataSourceCCloudQuotaTemplateV1() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCCloudQuotaTemplateV1Read,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"domain_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"project_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+
+			"quota_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceCCloudQuotaTemplateV1Read(d *schema.ResourceData, meta interface{}) error {
+	domainID := d.Get("domain_id").(string)
+	projectID := d.Get("project_id").(string)
+
+	config := meta.(*Config)
+	client, err := config.limesV1Client(GetRegion(d, config))
+	if err != nil {
+		return fmt.Errorf("Error creating OpenStack limes client: %s", err)
+	}
+
+	quota, err := projects.Get(client, domainID, projectID, projects.GetOpts{}).Extract()
+	if err != nil {
+		return fmt.Errorf("Error getting Limes project: %s", err)
+	}
+
+	exists := func(s string) bool { _, ok := quota.Services[s]; return ok }
+	template := make(map[string]map[string]uint64)
+	for service, resources := range limesServices {
+		srv := quota.Services[limesServiceType(service, exists)]
+		if srv == nil {
+			continue
+		}
+
+		res := make(map[string]uint64)
+		for resource, r := range srv.Resources {
+			// externally managed quota cannot be applied
+			if r.Quota == nil || r.ExternallyManaged {
+				continue
+			}
+			unit, ok := resources[resource]
+			if !ok {
+				if !limesDynamicServices[service] {
+					continue
+				}
+				unit = r.Unit
+			}
+			res[resource] = *limesQuotaValue(r, unit)
+		}
+
+		if len(res) > 0 {
+			template[service] = res
+		}
+	}
+
+	// the map keys are sorted by the JSON encoder
+	v, err := json.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("Error marshalling the quota template: %s", err)
+	}
+
+	log.Printf("[DEBUG] Retrieved %s/%s ccloud_quota_template_v1: %s", domainID, projectID, v)
+
+	d.SetId(projectID)
+	d.Set("quota_json", string(v))
+
+	d.Set("region", GetRegion(d, config))
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package ccloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sapcc/gophercloud-sapcc/resources/v1/projects"
+	"github.com/sapcc/limes"
+)
+
+func TestDataSourceCCloudQuotaTemplateV1RoundTrip(t *testing.T) {
+	// the report units differ from the schema units
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","services":[` +
+			`{"type":"compute","area":"compute","resources":[` +
+			`{"name":"cores","quota":10,"usage":0},` +
+			`{"name":"ram","unit":"GiB","quota":20,"usage":0},` +
+			`{"name":"instances","quota":5,"usage":0,"externally_managed":true}]},` +
+			`{"type":"volumev3","area":"storage","resources":[` +
+			`{"name":"capacity","unit":"TiB","quota":2,"usage":0}]},` +
+			`{"type":"object-store","area":"storage","resources":[` +
+			`{"name":"capacity","unit":"GiB","quota":1,"usage":0},` +
+			`{"name":"capacity_custom","unit":"MiB","quota":3,"usage":0}]}]}}`))
+	}))
+	defer server.Close()
+
+	config := testConfig(server)
+	d := schema.TestResourceDataRaw(t, dataSourceCCloudQuotaTemplateV1().Schema, map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+	})
+	if err := dataSourceCCloudQuotaTemplateV1Read(d, config); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `{"compute":{"cores":10,"ram":20480},"object-store":{"capacity":1073741824,"capacity_custom":3},"volumev2":{"capacity":2048}}`
+	if v := d.Get("quota_json").(string); v != expected {
+		t.Fatalf("expected %s, got %s", expected, v)
+	}
+
+	// the template applied through the quota_json changes nothing
+	client, err := config.limesV1Client("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	project, err := projects.Get(client, "d1", "p1", projects.GetOpts{}).Extract()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exists := func(s string) bool { _, ok := project.Services[s]; return ok }
+	services, err := expandLimesQuotaJSON(d.Get("quota_json").(string))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	services = limesAliasQuotaRequest(services, exists)
+	if err := limesResolveDynamicResources(services, project); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var count int
+	for service, srv := range services {
+		for resource, v := range srv.Resources {
+			count++
+			r := project.Services[service].Resources[resource]
+			if r == nil || r.Quota == nil {
+				t.Errorf("unexpected %s/%s resource", service, resource)
+				continue
+			}
+			if v, err := v.ConvertTo(r.Unit); err != nil || v.Value != *r.Quota {
+				t.Errorf("%s/%s: expected %s, got %s (%v)", service, resource, limes.ValueWithUnit{Value: *r.Quota, Unit: r.Unit}.String(), v.String(), err)
+			}
+		}
+	}
+	if count != 5 {
+		t.Errorf("expected 5 requested resources, got %d", count)
+	}
+}
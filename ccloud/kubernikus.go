@@ -25,8 +25,9 @@ var (
 
 type kubernikus struct {
 	operations.Client
-	provider  *gophercloud.ProviderClient
-	userAgent string
+	provider     *gophercloud.ProviderClient
+	userAgent    string
+	serviceToken string
 }
 
 type kubernikusLogger struct{}
@@ -118,7 +119,7 @@ func newKubernikusV1(c *Config, eo gophercloud.EndpointOpts) (*kubernikus, error
 
 	operations := operations.New(transport, strfmt.Default)
 
-	return &kubernikus{*operations, c.OsClient, httpclient.TerraformUserAgent(c.TerraformVersion)}, nil
+	return &kubernikus{*operations, c.OsClient, httpclient.TerraformUserAgent(c.TerraformVersion), c.ServiceToken}, nil
 }
 
 func (k *kubernikus) authFunc() runtime.ClientAuthInfoWriterFunc {
@@ -126,6 +127,9 @@ func (k *kubernikus) authFunc() runtime.ClientAuthInfoWriterFunc {
 		func(req runtime.ClientRequest, reg strfmt.Registry) error {
 			req.SetHeaderParam("X-AUTH-TOKEN", k.provider.Token())
 			req.SetHeaderParam("User-Agent", k.userAgent)
+			if k.serviceToken != "" {
+				req.SetHeaderParam(serviceTokenHeader, k.serviceToken)
+			}
 			return nil
 		})
 }
@@ -1,18 +1,45 @@
 package ccloud
 
 import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-plugin-sdk/meta"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 
+	osClient "github.com/gophercloud/utils/client"
 	"github.com/gophercloud/utils/terraform/auth"
 	"github.com/gophercloud/utils/terraform/mutexkv"
 )
 
+// The provider version and the build commit, which are set by the main
+// package.
+var (
+	ProviderVersion = "dev"
+	ProviderCommit  string
+)
+
 // Use openstackbase.Config as the base/foundation of this provider's
 // Config struct.
 type Config struct {
 	auth.Config
+
+	DefaultTags         map[string]string
+	WaitForMaintenance  bool
+	TelemetryEndpoint   string
+	MaxParallelRequests int
+	ServiceToken        string
+	EnabledFeatures     []string
+
+	// domainNames caches the Keystone domain names keyed by the domain ID
+	domainNames sync.Map
+
+	// serviceCatalogs caches the probed service endpoints keyed by the region
+	serviceCatalogs sync.Map
 }
 
 // Provider returns a schema.Provider for OpenStack.
@@ -96,6 +123,29 @@ func Provider() terraform.ResourceProvider {
 				Description: descriptions["password"],
 			},
 
+			"passcode": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("OS_TOTP_CODE", ""),
+				Description: descriptions["passcode"],
+			},
+
+			"trust_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OS_TRUST_ID", ""),
+				Description: descriptions["trust_id"],
+			},
+
+			"service_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("OS_SERVICE_TOKEN", ""),
+				Description: descriptions["service_token"],
+			},
+
 			"token": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -166,6 +216,10 @@ func Provider() terraform.ResourceProvider {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OS_ENDPOINT_TYPE", ""),
+				Description: descriptions["endpoint_type"],
+				ValidateFunc: validation.StringInSlice([]string{
+					"", "public", "publicURL", "internal", "internalURL", "admin", "adminURL",
+				}, false),
 			},
 
 			"cacert_file": {
@@ -175,6 +229,13 @@ func Provider() terraform.ResourceProvider {
 				Description: descriptions["cacert_file"],
 			},
 
+			"cacert_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OS_CACERT_PEM", ""),
+				Description: descriptions["cacert_pem"],
+			},
+
 			"cert": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -217,12 +278,54 @@ func Provider() terraform.ResourceProvider {
 				Description: descriptions["max_retries"],
 			},
 
+			"max_backoff_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  descriptions["max_backoff_retries"],
+			},
+
+			"max_parallel_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  descriptions["max_parallel_requests"],
+			},
+
+			"request_id_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "req-",
+				Description: descriptions["request_id_prefix"],
+			},
+
 			"endpoint_overrides": {
 				Type:        schema.TypeMap,
 				Optional:    true,
 				Description: descriptions["endpoint_overrides"],
 			},
 
+			"wait_for_maintenance": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["wait_for_maintenance"],
+			},
+
+			"telemetry_endpoint": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["telemetry_endpoint"],
+			},
+
+			"default_tags": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: descriptions["default_tags"],
+			},
+
 			"disable_no_cache_header": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -236,25 +339,33 @@ func Provider() terraform.ResourceProvider {
 			"ccloud_arc_agent_ids_v1":           dataSourceCCloudArcAgentIDsV1(),
 			"ccloud_arc_job_v1":                 dataSourceCCloudArcJobV1(),
 			"ccloud_arc_job_ids_v1":             dataSourceCCloudArcJobIDsV1(),
+			"ccloud_auth_scope_v1":              dataSourceCCloudAuthScopeV1(),
 			"ccloud_automation_v1":              dataSourceCCloudAutomationV1(),
+			"ccloud_automation_run_v1":          dataSourceCCloudAutomationRunV1(),
 			"ccloud_billing_domain_masterdata":  dataSourceCCloudBillingDomainMasterdata(),
 			"ccloud_billing_project_masterdata": dataSourceCCloudBillingProjectMasterdata(),
+			"ccloud_endpoints_v1":               dataSourceCCloudEndpointsV1(),
+			"ccloud_provider_info_v1":           dataSourceCCloudProviderInfoV1(),
+			"ccloud_quota_rates_v1":             dataSourceCCloudQuotaRatesV1(),
+			"ccloud_quota_template_v1":          dataSourceCCloudQuotaTemplateV1(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"ccloud_arc_agent_bootstrap_v1":     resourceCCloudArcAgentBootstrapV1(),
-			"ccloud_arc_agent_v1":               resourceCCloudArcAgentV1(),
-			"ccloud_arc_job_v1":                 resourceCCloudArcJobV1(),
-			"ccloud_automation_v1":              resourceCCloudAutomationV1(),
-			"ccloud_automation_run_v1":          resourceCCloudAutomationRunV1(),
-			"ccloud_billing_domain_masterdata":  resourceCCloudBillingDomainMasterdata(),
-			"ccloud_billing_project_masterdata": resourceCCloudBillingProjectMasterdata(),
-			"ccloud_quota":                      resourceCCloudProjectQuotaV1(),
-			"ccloud_quota_v1":                   resourceCCloudProjectQuotaV1(),
-			"ccloud_project_quota_v1":           resourceCCloudProjectQuotaV1(),
-			"ccloud_domain_quota_v1":            resourceCCloudDomainQuotaV1(),
-			"ccloud_kubernetes":                 resourceCCloudKubernetesV1(),
-			"ccloud_kubernetes_v1":              resourceCCloudKubernetesV1(),
+			"ccloud_arc_agent_bootstrap_v1":      resourceCCloudArcAgentBootstrapV1(),
+			"ccloud_arc_agent_v1":                resourceCCloudArcAgentV1(),
+			"ccloud_arc_job_v1":                  resourceCCloudArcJobV1(),
+			"ccloud_automation_v1":               resourceCCloudAutomationV1(),
+			"ccloud_automation_run_v1":           resourceCCloudAutomationRunV1(),
+			"ccloud_billing_domain_masterdata":   resourceCCloudBillingDomainMasterdata(),
+			"ccloud_billing_project_masterdata":  resourceCCloudBillingProjectMasterdata(),
+			"ccloud_billing_projects_masterdata": resourceCCloudBillingProjectsMasterdata(),
+			"ccloud_quota":                       resourceCCloudProjectQuotaV1(),
+			"ccloud_quota_v1":                    resourceCCloudProjectQuotaV1(),
+			"ccloud_project_quota_v1":            resourceCCloudProjectQuotaV1(),
+			"ccloud_domain_quota_v1":             resourceCCloudDomainQuotaV1(),
+			"ccloud_quota_project_ready_v1":      resourceCCloudQuotaProjectReadyV1(),
+			"ccloud_kubernetes":                  resourceCCloudKubernetesV1(),
+			"ccloud_kubernetes_v1":               resourceCCloudKubernetesV1(),
 		},
 	}
 
@@ -299,6 +410,12 @@ func init() {
 
 		"password": "Password to login with.",
 
+		"passcode": "TOTP passcode to login with, along with the password.",
+
+		"trust_id": "The ID of the Keystone trust to scope the token to (Identity v3).",
+
+		"service_token": "A service token, which is sent along with the user authentication.",
+
 		"token": "Authentication token to use as an alternative to username/password.",
 
 		"user_domain_name": "The name of the domain where the user resides (Identity v3).",
@@ -319,15 +436,25 @@ func init() {
 
 		"cacert_file": "A Custom CA certificate.",
 
+		"cacert_pem": "Custom CA certificates in PEM format to trust additionally.",
+
 		"cert": "A client certificate to authenticate with.",
 
 		"key": "A client private key to authenticate with.",
 
 		"endpoint_type": "The catalog endpoint type to use.",
 
+		"request_id_prefix": "The prefix of the request ID, which is sent along with every HTTP request.",
+
 		"endpoint_overrides": "A map of services with an endpoint to override what was\n" +
 			"from the Keystone catalog",
 
+		"wait_for_maintenance": "If set to `true`, the quota updates are retried, while Limes is in maintenance.",
+
+		"telemetry_endpoint": "The statsd `host:port` endpoint to send the quota update metrics to.",
+
+		"default_tags": "A map of tags, which are merged into the tags of every taggable resource.",
+
 		"disable_no_cache_header": "If set to `true`, the HTTP `Cache-Control: no-cache` header will not be added by default to all API requests.",
 
 		"delayed_auth": "If set to `false`, OpenStack authorization will be perfomed,\n" +
@@ -337,12 +464,19 @@ func init() {
 			"automatically, if the initial auth token get expired. Defaults to `true`",
 
 		"max_retries": "How many times HTTP connection should be retried until giving up.",
+
+		"max_backoff_retries": "How many times HTTP request should be retried, when the API\n" +
+			"responds with a 429 (Too Many Requests) status code. Defaults to `3` or to the\n" +
+			"`max_retries`, when it is larger.",
+
+		"max_parallel_requests": "How many HTTP requests can be sent to the API in parallel.\n" +
+			"Defaults to `0`, which means unlimited.",
 	}
 }
 
 func configureProvider(d *schema.ResourceData, terraformVersion string) (interface{}, error) {
 	config := Config{
-		auth.Config{
+		Config: auth.Config{
 			CACertFile:                  d.Get("cacert_file").(string),
 			ClientCertFile:              d.Get("cert").(string),
 			ClientKeyFile:               d.Get("key").(string),
@@ -352,7 +486,7 @@ func configureProvider(d *schema.ResourceData, terraformVersion string) (interfa
 			DomainName:                  d.Get("domain_name").(string),
 			EndpointOverrides:           d.Get("endpoint_overrides").(map[string]interface{}),
 			EndpointType:                d.Get("endpoint_type").(string),
-			IdentityEndpoint:            d.Get("auth_url").(string),
+			IdentityEndpoint:            normalizeAuthURL(d.Get("auth_url").(string)),
 			Password:                    d.Get("password").(string),
 			ProjectDomainID:             d.Get("project_domain_id").(string),
 			ProjectDomainName:           d.Get("project_domain_name").(string),
@@ -375,6 +509,11 @@ func configureProvider(d *schema.ResourceData, terraformVersion string) (interfa
 			SDKVersion:                  meta.SDKVersionString(),
 			MutexKV:                     mutexkv.NewMutexKV(),
 		},
+		DefaultTags:         expandToMapStringString(d.Get("default_tags").(map[string]interface{})),
+		WaitForMaintenance:  d.Get("wait_for_maintenance").(bool),
+		TelemetryEndpoint:   d.Get("telemetry_endpoint").(string),
+		MaxParallelRequests: d.Get("max_parallel_requests").(int),
+		ServiceToken:        d.Get("service_token").(string),
 	}
 
 	v, ok := d.GetOkExists("insecure")
@@ -383,9 +522,132 @@ func configureProvider(d *schema.ResourceData, terraformVersion string) (interfa
 		config.Insecure = &insecure
 	}
 
+	passcode := d.Get("passcode").(string)
+	trustID := d.Get("trust_id").(string)
+	cacertPEM := d.Get("cacert_pem").(string)
+	delayedAuth := config.DelayedAuth
+	if passcode != "" || trustID != "" || cacertPEM != "" {
+		// the authentication is performed below, when the client is ready
+		config.DelayedAuth = true
+	}
+
+	if config.ServiceToken != "" && config.Cloud == "" && config.Password == "" && config.Token == "" && config.ApplicationCredentialSecret == "" {
+		return nil, fmt.Errorf("The service_token can be used only along with the password, token or application credential authentication")
+	}
+
 	if err := config.LoadAndValidate(); err != nil {
 		return nil, err
 	}
 
+	if cacertPEM != "" {
+		if err := config.appendCACertPEM(cacertPEM); err != nil {
+			return nil, err
+		}
+	}
+
+	if passcode != "" || trustID != "" {
+		if err := config.authenticateWithOptions(passcode, trustID); err != nil {
+			return nil, err
+		}
+	} else if !delayedAuth {
+		if err := config.Authenticate(); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("[DEBUG] OpenStack Identity endpoint: %s", config.OsClient.IdentityEndpoint)
+
+	// the catalog of the provider region is probed once the client is
+	// authenticated, otherwise on the first use of the region services
+	if !config.DelayedAuth {
+		if _, err := config.probeServiceCatalog(""); err != nil {
+			return nil, err
+		}
+	}
+
+	// the 429 responses are retried by default, when max_backoff_retries is
+	// unset, the larger max_retries extends the default
+	retries := defaultMaxBackoffRetries
+	if v, ok := d.GetOkExists("max_backoff_retries"); ok {
+		retries = v.(int)
+	} else if config.MaxRetries > retries {
+		retries = config.MaxRetries
+	}
+	// gophercloud treats zero as its own default, i.e. the retries are
+	// disabled by unsetting the backoff func
+	config.OsClient.MaxBackoffRetries = uint(retries)
+	config.OsClient.RetryBackoffFunc = nil
+	if retries > 0 {
+		config.OsClient.RetryBackoffFunc = retryBackoffFunc
+	}
+
+	if rt, ok := config.OsClient.HTTPClient.Transport.(*osClient.RoundTripper); ok {
+		rt.Rt = newRequestIDRoundTripper(rt.Rt, d.Get("request_id_prefix").(string))
+	}
+
+	if v := config.MaxParallelRequests; v > 0 {
+		if rt, ok := config.OsClient.HTTPClient.Transport.(*osClient.RoundTripper); ok {
+			rt.Rt = newLimitedRoundTripper(rt.Rt, v)
+		}
+	}
+
+	config.EnabledFeatures = providerEnabledFeatures(d)
+
 	return &config, nil
 }
+
+// providerEnabledFeatures returns the sorted list of the optional provider
+// features, which are enabled in the provider configuration.
+func providerEnabledFeatures(d *schema.ResourceData) []string {
+	var features []string
+	for _, k := range []string{
+		"allow_reauth",
+		"cacert_pem",
+		"default_tags",
+		"delayed_auth",
+		"disable_no_cache_header",
+		"endpoint_overrides",
+		"insecure",
+		"max_parallel_requests",
+		"max_retries",
+		"passcode",
+		"service_token",
+		"telemetry_endpoint",
+		"trust_id",
+		"wait_for_maintenance",
+	} {
+		switch v := d.Get(k).(type) {
+		case bool:
+			if !v {
+				continue
+			}
+		case int:
+			if v == 0 {
+				continue
+			}
+		case string:
+			if v == "" {
+				continue
+			}
+		case map[string]interface{}:
+			if len(v) == 0 {
+				continue
+			}
+		}
+		features = append(features, k)
+	}
+
+	return features
+}
+
+// normalizeAuthURL trims whitespaces and redundant trailing slashes from the
+// Identity endpoint. When the endpoint doesn't contain an API version, it is
+// discovered by gophercloud during the authentication, preferring v3.
+func normalizeAuthURL(authURL string) string {
+	authURL = strings.TrimRight(strings.TrimSpace(authURL), "/")
+	if authURL == "" {
+		return ""
+	}
+
+	return authURL + "/"
+}
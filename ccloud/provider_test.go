@@ -0,0 +1,89 @@
+package ccloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestNormalizeAuthURL(t *testing.T) {
+	cases := []struct {
+		authURL  string
+		expected string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"https://identity.example.com", "https://identity.example.com/"},
+		{"https://identity.example.com/", "https://identity.example.com/"},
+		{"https://identity.example.com//", "https://identity.example.com/"},
+		{"https://identity.example.com/v3", "https://identity.example.com/v3/"},
+		{"https://identity.example.com/v3/", "https://identity.example.com/v3/"},
+		{" https://identity.example.com/v3// ", "https://identity.example.com/v3/"},
+	}
+
+	for _, c := range cases {
+		if v := normalizeAuthURL(c.authURL); v != c.expected {
+			t.Errorf("normalizeAuthURL(%q): expected %q, got %q", c.authURL, c.expected, v)
+		}
+	}
+}
+
+func TestConfigureProviderBackoffRetries(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected uint
+		err      bool
+	}{
+		{"default", map[string]interface{}{}, defaultMaxBackoffRetries, false},
+		{"max_retries", map[string]interface{}{"max_retries": 5}, 5, false},
+		{"max_backoff_retries", map[string]interface{}{"max_retries": 5, "max_backoff_retries": 1}, 1, false},
+		{"disabled", map[string]interface{}{"max_backoff_retries": 0}, 0, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls = 0
+			c.raw["auth_url"] = server.URL + "/v3"
+			c.raw["token"] = "token"
+			d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, c.raw)
+			v, err := configureProvider(d, "0.12.0")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			client := v.(*Config).OsClient
+			if client.MaxBackoffRetries != c.expected {
+				t.Errorf("expected %d retries, got %d", c.expected, client.MaxBackoffRetries)
+			}
+
+			_, err = client.Request("GET", server.URL, &gophercloud.RequestOpts{})
+			if c.err {
+				if _, ok := err.(gophercloud.ErrDefault429); !ok {
+					t.Fatalf("expected a 429 error, got %T: %v", err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if calls != 2 {
+				t.Fatalf("expected 2 requests, got %d", calls)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ccloud
 import (
 	"fmt"
 	"log"
+	"reflect"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
@@ -27,6 +28,8 @@ func resourceCCloudArcAgentV1() *schema.Resource {
 			Delete: schema.DefaultTimeout(30 * time.Minute),
 		},
 
+		CustomizeDiff: resourceCCloudArcAgentV1CustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"region": {
 				Type:     schema.TypeString,
@@ -104,6 +107,12 @@ func resourceCCloudArcAgentV1() *schema.Resource {
 				Computed: true,
 			},
 
+			"effective_tags": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
 			"facts": {
 				Type:     schema.TypeMap,
 				Computed: true,
@@ -135,11 +144,14 @@ func resourceCCloudArcAgentV1Create(d *schema.ResourceData, meta interface{}) er
 
 	d.SetId(agent.AgentID)
 
-	err = updateArcAgentTagsV1(arcClient, d.Id(), nil, d.Get("tags"))
+	tags := mergeDefaultTags(config.DefaultTags, d.Get("tags"))
+	err = updateArcAgentTagsV1(arcClient, d.Id(), nil, tags)
 	if err != nil {
 		return err
 	}
 
+	d.Set("effective_tags", tags)
+
 	return resourceCCloudArcAgentV1Read(d, meta)
 }
 
@@ -167,15 +179,36 @@ func resourceCCloudArcAgentV1Update(d *schema.ResourceData, meta interface{}) er
 		return fmt.Errorf("Error creating OpenStack Arc client: %s", err)
 	}
 
-	oldTags, newTags := d.GetChange("tags")
+	// the effective tags are empty in the states, created before the default
+	// tags were introduced, the applied tags are tracked only in the "tags"
+	// attribute there
+	oldTags, _ := d.GetChange("effective_tags")
+	if len(oldTags.(map[string]interface{})) == 0 {
+		oldTags, _ = d.GetChange("tags")
+	}
+	newTags := mergeDefaultTags(config.DefaultTags, d.Get("tags"))
 	err = updateArcAgentTagsV1(arcClient, d.Id(), oldTags, newTags)
 	if err != nil {
 		return err
 	}
 
+	d.Set("effective_tags", newTags)
+
 	return resourceCCloudArcAgentV1Read(d, meta)
 }
 
+func resourceCCloudArcAgentV1CustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	config := meta.(*Config)
+
+	// reconcile the provider-level default tags changes
+	tags := mergeDefaultTags(config.DefaultTags, d.Get("tags"))
+	if !reflect.DeepEqual(tags, d.Get("effective_tags").(map[string]interface{})) {
+		return d.SetNew("effective_tags", tags)
+	}
+
+	return nil
+}
+
 func resourceCCloudArcAgentV1Delete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*Config)
 	arcClient, err := config.arcV1Client(GetRegion(d, config))
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gophercloud/gophercloud"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/sapcc/gophercloud-sapcc/billing/masterdata/projects"
@@ -224,19 +225,45 @@ func resourceCCloudBillingProjectMasterdata() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+
+			"project_region": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"masterdata_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"skip_if_unavailable": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 		},
 	}
 }
 
 func resourceCCloudBillingProjectMasterdataCreateOrUpdate(d *schema.ResourceData, meta interface{}) error {
+	projectID := d.Get("project_id").(string)
+
 	config := meta.(*Config)
 	billing, err := config.billingClient(GetRegion(d, config))
 	if err != nil {
+		if _, ok := err.(errServiceNotAvailable); ok && d.Get("skip_if_unavailable").(bool) {
+			log.Printf("[WARN] Skipping the billing project masterdata update: %s", err)
+			if d.Id() == "" {
+				if projectID == "" {
+					projectID = resource.UniqueId()
+				}
+				d.SetId(projectID)
+			}
+			return nil
+		}
 		return fmt.Errorf("Error creating OpenStack billing client: %s", err)
 	}
 
-	projectID := d.Get("project_id").(string)
-
 	var project *projects.Project
 	if d.Id() == "" && projectID == "" {
 		// first call, expecting to get current scope project
@@ -320,6 +347,10 @@ func resourceCCloudBillingProjectMasterdataRead(d *schema.ResourceData, meta int
 	config := meta.(*Config)
 	billing, err := config.billingClient(GetRegion(d, config))
 	if err != nil {
+		if _, ok := err.(errServiceNotAvailable); ok && d.Get("skip_if_unavailable").(bool) {
+			log.Printf("[WARN] Skipping the billing project masterdata read: %s", err)
+			return nil
+		}
 		return fmt.Errorf("Error creating OpenStack billing client: %s", err)
 	}
 
@@ -358,6 +389,8 @@ func resourceCCloudBillingProjectMasterdataRead(d *schema.ResourceData, meta int
 	d.Set("is_complete", project.IsComplete)
 	d.Set("missing_attributes", project.MissingAttributes)
 	d.Set("collector", project.Collector)
+	d.Set("project_region", project.Region)
+	d.Set("masterdata_json", billingProjectMasterdataJSON(project))
 
 	d.Set("region", GetRegion(d, config))
 
@@ -0,0 +1,108 @@
+package ccloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestResourceCCloudBillingProjectMasterdataReadCollectorRegion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/masterdata/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project_id":"p1","collector":"collector1","region":"r2"}`))
+	}))
+	defer server.Close()
+
+	config := testConfig(server)
+	config.Region = "r1"
+
+	cases := []struct {
+		name     string
+		resource *schema.Resource
+		read     schema.ReadFunc
+	}{
+		{"resource", resourceCCloudBillingProjectMasterdata(), resourceCCloudBillingProjectMasterdataRead},
+		{"data source", dataSourceCCloudBillingProjectMasterdata(), dataSourceCCloudBillingProjectMasterdataRead},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, c.resource.Schema, map[string]interface{}{"project_id": "p1"})
+		d.SetId("p1")
+		if err := c.read(d, config); err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+
+		expected := map[string]interface{}{
+			"collector":      "collector1",
+			"project_region": "r2",
+			// the provider region is not overridden by the masterdata region
+			"region": "r1",
+		}
+		for k, v := range expected {
+			if d.Get(k) != v {
+				t.Errorf("%s: expected %q %s, got %q", c.name, v, k, d.Get(k))
+			}
+		}
+	}
+}
+
+func TestResourceCCloudBillingProjectMasterdataUnavailable(t *testing.T) {
+	cases := []struct {
+		name string
+		skip bool
+		err  string
+	}{
+		{"error", false, `The "sapcc-billing" service is not available in the "r1" region`},
+		{"skip", true, ""},
+	}
+
+	for _, c := range cases {
+		config := &Config{}
+		config.Region = "r1"
+		config.OsClient = &gophercloud.ProviderClient{
+			EndpointLocator: func(eo gophercloud.EndpointOpts) (string, error) {
+				if eo.Type == "sapcc-billing" {
+					return "", &gophercloud.ErrEndpointNotFound{}
+				}
+				return "http://127.0.0.1:0/", nil
+			},
+		}
+
+		d := schema.TestResourceDataRaw(t, resourceCCloudBillingProjectMasterdata().Schema, map[string]interface{}{
+			"project_id":          "p1",
+			"skip_if_unavailable": c.skip,
+		})
+
+		funcs := []struct {
+			name string
+			f    func(*schema.ResourceData, interface{}) error
+		}{
+			{"create", resourceCCloudBillingProjectMasterdataCreateOrUpdate},
+			{"read", resourceCCloudBillingProjectMasterdataRead},
+			{"update", resourceCCloudBillingProjectMasterdataCreateOrUpdate},
+		}
+		for _, f := range funcs {
+			err := f.f(d, config)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Errorf("%s %s: expected the %q error, got %v", c.name, f.name, c.err, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s %s: unexpected error: %s", c.name, f.name, err)
+			}
+			if d.Id() != "p1" {
+				t.Errorf("%s %s: expected \"p1\" ID, got %q", c.name, f.name, d.Id())
+			}
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package ccloud
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sapcc/gophercloud-sapcc/billing/masterdata/projects"
+)
+
+const (
+	billingProjectsMasterdataApplied = "applied"
+	billingProjectsMasterdataPending = "pending"
+	billingProjectsMasterdataFailed  = "failed"
+)
+
+func resourceCCloudBillingProjectsMasterdata() *schema.Resource {
+	return &schema.Resource{
+		Read:   resourceCCloudBillingProjectsMasterdataRead,
+		Update: resourceCCloudBillingProjectsMasterdataCreateOrUpdate,
+		Create: resourceCCloudBillingProjectsMasterdataCreateOrUpdate,
+		Delete: schema.RemoveFromState,
+
+		CustomizeDiff: resourceCCloudBillingProjectsMasterdataCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"project_ids": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.NoZeroValues,
+				},
+			},
+
+			"cost_object": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"inherited": {
+							Type:          schema.TypeBool,
+							Optional:      true,
+							ConflictsWith: []string{"cost_object.0.name", "cost_object.0.type"},
+						},
+						"name": {
+							Type:          schema.TypeString,
+							Optional:      true,
+							ConflictsWith: []string{"cost_object.0.inherited"},
+						},
+						"type": {
+							Type:          schema.TypeString,
+							Optional:      true,
+							ConflictsWith: []string{"cost_object.0.inherited"},
+							ValidateFunc: validation.StringInSlice([]string{
+								"IO", "CC", "WBS", "SO",
+							}, false),
+						},
+					},
+				},
+			},
+
+			// computed parameters
+			"status": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceCCloudBillingProjectsMasterdataCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	// resume the partially failed update
+	for projectID, status := range d.Get("status").(map[string]interface{}) {
+		if status.(string) != billingProjectsMasterdataApplied {
+			log.Printf("[DEBUG] Billing project %s masterdata status is %q, scheduling an update", projectID, status)
+			return d.SetNewComputed("status")
+		}
+	}
+
+	return nil
+}
+
+func resourceCCloudBillingProjectsMasterdataCreateOrUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+	billing, err := config.billingClient(GetRegion(d, config))
+	if err != nil {
+		return fmt.Errorf("Error creating OpenStack billing client: %s", err)
+	}
+
+	costObject := billingProjectExpandCostObject(d.Get("cost_object"))
+	oldStatus := d.Get("status").(map[string]interface{})
+
+	var projectIDs []string
+	for _, v := range d.Get("project_ids").(*schema.Set).List() {
+		projectID := v.(string)
+		// when the cost object was not changed, update only not yet applied projects
+		if d.Id() != "" && !d.HasChange("cost_object") && oldStatus[projectID] == billingProjectsMasterdataApplied {
+			continue
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	errors := forEachConcurrently(projectIDs, config.MaxParallelRequests, func(projectID string) error {
+		return billingProjectsMasterdataUpdate(billing, projectID, costObject)
+	})
+
+	status := billingProjectsMasterdataStatus(d.Get("project_ids").(*schema.Set), errors)
+
+	if d.Id() == "" {
+		if len(errors) == len(status) {
+			return billingProjectsMasterdataError(errors)
+		}
+		d.SetId(resource.UniqueId())
+	}
+
+	// the partial status is kept in the state, the failed projects are
+	// retried on the next apply
+	d.Set("status", status)
+
+	if len(errors) > 0 {
+		return billingProjectsMasterdataError(errors)
+	}
+
+	return resourceCCloudBillingProjectsMasterdataRead(d, meta)
+}
+
+// billingProjectsMasterdataUpdate sets the cost object of the project
+// masterdata.
+func billingProjectsMasterdataUpdate(billing *gophercloud.ServiceClient, projectID string, costObject projects.CostObject) error {
+	project, err := projects.Get(billing, projectID).Extract()
+	if err != nil {
+		return fmt.Errorf("Error getting billing project masterdata: %s", err)
+	}
+
+	// API doesn't support partial update, thus prefilling the update options with the existing data
+	opts := projects.ProjectToUpdateOpts(project)
+	opts.CostObject = costObject
+
+	log.Printf("[DEBUG] Updating %s project masterdata: %+v", projectID, opts)
+
+	_, err = projects.Update(billing, projectID, opts).Extract()
+	if err != nil {
+		return fmt.Errorf("Error updating billing project masterdata: %s", err)
+	}
+
+	return nil
+}
+
+// billingProjectsMasterdataStatus returns the masterdata status of each
+// project after the update.
+func billingProjectsMasterdataStatus(projectIDs *schema.Set, errors map[string]error) map[string]string {
+	status := make(map[string]string)
+	for _, v := range projectIDs.List() {
+		projectID := v.(string)
+		if err, ok := errors[projectID]; ok {
+			status[projectID] = billingProjectsMasterdataFailed
+			log.Printf("[DEBUG] Failed to update %s project masterdata: %s", projectID, err)
+			continue
+		}
+		status[projectID] = billingProjectsMasterdataApplied
+	}
+
+	return status
+}
+
+func resourceCCloudBillingProjectsMasterdataRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*Config)
+	billing, err := config.billingClient(GetRegion(d, config))
+	if err != nil {
+		return fmt.Errorf("Error creating OpenStack billing client: %s", err)
+	}
+
+	costObject := billingProjectExpandCostObject(d.Get("cost_object"))
+
+	var projectIDs []string
+	for _, v := range d.Get("project_ids").(*schema.Set).List() {
+		projectIDs = append(projectIDs, v.(string))
+	}
+
+	var mutex sync.Mutex
+	status := make(map[string]string)
+	errors := forEachConcurrently(projectIDs, config.MaxParallelRequests, func(projectID string) error {
+		project, err := projects.Get(billing, projectID).Extract()
+		if err != nil {
+			if _, ok := err.(gophercloud.ErrDefault404); ok {
+				mutex.Lock()
+				status[projectID] = billingProjectsMasterdataPending
+				mutex.Unlock()
+				return nil
+			}
+			return fmt.Errorf("Error getting billing project masterdata: %s", err)
+		}
+
+		log.Printf("[DEBUG] Retrieved project masterdata: %+v", project)
+
+		v := billingProjectsMasterdataPending
+		if project.CostObject.Inherited == costObject.Inherited &&
+			(costObject.Inherited || project.CostObject == costObject) {
+			v = billingProjectsMasterdataApplied
+		}
+
+		mutex.Lock()
+		status[projectID] = v
+		mutex.Unlock()
+
+		return nil
+	})
+	if len(errors) > 0 {
+		return billingProjectsMasterdataError(errors)
+	}
+
+	d.Set("status", status)
+
+	d.Set("region", GetRegion(d, config))
+
+	return nil
+}
+
+func billingProjectsMasterdataError(errors map[string]error) error {
+	var msgs []string
+	for projectID, err := range errors {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", projectID, err))
+	}
+	sort.Strings(msgs)
+
+	return fmt.Errorf("Error processing %d billing projects masterdata:\n%s", len(errors), strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,144 @@
+package ccloud
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sapcc/gophercloud-sapcc/billing/masterdata/projects"
+)
+
+// testBillingProjectsMasterdataServer mocks the billing API, which fails for
+// the p3 project, and records the updated cost objects.
+func testBillingProjectsMasterdataServer(t *testing.T, updated map[string]projects.CostObject) *httptest.Server {
+	var mutex sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		projectID := strings.TrimPrefix(r.URL.Path, "/masterdata/projects/")
+		if projectID == "p3" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"project_id":                        projectID,
+				"responsible_primary_contact_id":    "D000000",
+				"responsible_primary_contact_email": "mail@example.com",
+			})
+		case "PUT":
+			var v struct {
+				CostObject projects.CostObject `json:"cost_object"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+				t.Errorf("failed to decode the masterdata: %s", err)
+			}
+			mutex.Lock()
+			updated[projectID] = v.CostObject
+			mutex.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"project_id": projectID})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+}
+
+func TestBillingProjectsMasterdataPartialFailure(t *testing.T) {
+	updated := make(map[string]projects.CostObject)
+	server := testBillingProjectsMasterdataServer(t, updated)
+	defer server.Close()
+
+	client := testServiceClient(server)
+	costObject := projects.CostObject{Name: "123", Type: "IO"}
+	projectIDs := []string{"p1", "p2", "p3"}
+
+	errors := forEachConcurrently(projectIDs, 2, func(projectID string) error {
+		return billingProjectsMasterdataUpdate(client, projectID, costObject)
+	})
+	if len(errors) != 1 || errors["p3"] == nil {
+		t.Fatalf("expected the p3 error only, got %v", errors)
+	}
+
+	expected := map[string]projects.CostObject{"p1": costObject, "p2": costObject}
+	if !reflect.DeepEqual(updated, expected) {
+		t.Errorf("expected %v updated, got %v", expected, updated)
+	}
+
+	status := billingProjectsMasterdataStatus(schema.NewSet(schema.HashString, []interface{}{"p1", "p2", "p3"}), errors)
+	expectedStatus := map[string]string{
+		"p1": billingProjectsMasterdataApplied,
+		"p2": billingProjectsMasterdataApplied,
+		"p3": billingProjectsMasterdataFailed,
+	}
+	if !reflect.DeepEqual(status, expectedStatus) {
+		t.Errorf("expected %v status, got %v", expectedStatus, status)
+	}
+}
+
+func TestBillingProjectsMasterdataForEachLimit(t *testing.T) {
+	var mutex sync.Mutex
+	var running, max int
+	projectIDs := make([]string, 20)
+	for i := range projectIDs {
+		projectIDs[i] = string(rune('a' + i))
+	}
+
+	forEachConcurrently(projectIDs, 3, func(string) error {
+		mutex.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+		return nil
+	})
+
+	if max > 3 {
+		t.Fatalf("expected at most 3 concurrent calls, got %d", max)
+	}
+}
+
+func TestResourceCCloudBillingProjectsMasterdataCreatePartialFailure(t *testing.T) {
+	updated := make(map[string]projects.CostObject)
+	server := testBillingProjectsMasterdataServer(t, updated)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceCCloudBillingProjectsMasterdata().Schema, map[string]interface{}{
+		"project_ids": []interface{}{"p1", "p2", "p3"},
+		"cost_object": []interface{}{map[string]interface{}{"name": "123", "type": "IO"}},
+	})
+
+	err := resourceCCloudBillingProjectsMasterdataCreateOrUpdate(d, testConfig(server))
+	if err == nil || !strings.Contains(err.Error(), "p3: ") || strings.Contains(err.Error(), "p1: ") {
+		t.Fatalf("expected the error naming the p3 project, got %v", err)
+	}
+	if d.Id() == "" {
+		t.Fatal("expected the resource to be created with the partial status")
+	}
+
+	expectedStatus := map[string]interface{}{
+		"p1": billingProjectsMasterdataApplied,
+		"p2": billingProjectsMasterdataApplied,
+		"p3": billingProjectsMasterdataFailed,
+	}
+	if v := d.Get("status"); !reflect.DeepEqual(v, expectedStatus) {
+		t.Errorf("expected %v status, got %v", expectedStatus, v)
+	}
+	if len(updated) != 2 {
+		t.Errorf("expected 2 updated projects, got %v", updated)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"log"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sapcc/gophercloud-sapcc/resources/v1/clusters"
 	"github.com/sapcc/gophercloud-sapcc/resources/v1/domains"
+	"github.com/sapcc/gophercloud-sapcc/resources/v1/projects"
 	"github.com/sapcc/limes"
 )
 
@@ -33,6 +35,41 @@ func resourceCCloudDomainQuotaV1() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+
+			// computed quota breakdown per project
+			"projects": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"project_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"quota": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeInt},
+						},
+					},
+				},
+			},
+
+			"overcommit_factor": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeFloat},
+			},
+
+			"utilization_percent": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeFloat},
+			},
 		},
 	}
 
@@ -43,7 +80,7 @@ func resourceCCloudDomainQuotaV1() *schema.Resource {
 
 		for resource := range resources {
 			elem.Schema[resource] = &schema.Schema{
-				Type:     schema.TypeFloat,
+				Type:     schema.TypeInt,
 				Required: false,
 				Optional: true,
 				Computed: true,
@@ -78,16 +115,47 @@ func resourceCCloudDomainQuotaV1Read(d *schema.ResourceData, meta interface{}) e
 		return fmt.Errorf("Error getting Limes domain: %s", err)
 	}
 
+	for serviceType := range quota.Services {
+		if !limesKnownServiceType(serviceType) {
+			log.Printf("[WARN] Skipping the unsupported Limes %q service", serviceType)
+		}
+	}
+
+	exists := func(s string) bool { _, ok := quota.Services[s]; return ok }
+	utilizationPercent := make(map[string]float64)
 	for service, resources := range limesServices {
-		res := make(map[string]*uint64)
+		srv := quota.Services[limesServiceType(service, exists)]
+		res := make(map[string]int64)
 		for resource := range resources {
-			if quota.Services[service] == nil || quota.Services[service].Resources[resource] == nil {
+			if srv == nil || srv.Resources[resource] == nil {
 				continue
 			}
-			res[resource] = quota.Services[service].Resources[resource].DomainQuota
-			log.Printf("[QUOTA] %s.%s: %s", service, resource, toString(quota.Services[service].Resources[resource]))
+			if v := srv.Resources[resource].DomainQuota; v != nil {
+				res[resource] = int64(*v)
+			}
+			if v, ok := limesDomainUtilizationPercent(srv.Resources[resource]); ok {
+				utilizationPercent[limesResourceKey(service, resource)] = v
+			}
+			log.Printf("[QUOTA] %s.%s: %s", service, resource, toString(srv.Resources[resource]))
 		}
-		d.Set(sanitize(service), []map[string]*uint64{res})
+		d.Set(sanitize(service), []map[string]int64{res})
+	}
+	d.Set("utilization_percent", utilizationPercent)
+
+	// the project list may be forbidden for the domain quota managers
+	if reports, err := projects.List(limes, domainID, projects.ListOpts{}).ExtractProjects(); err != nil {
+		log.Printf("[WARN] Unable to list Limes domain projects: %s", err)
+		d.Set("projects", []map[string]interface{}{})
+	} else {
+		d.Set("projects", limesFlattenDomainProjects(reports))
+	}
+
+	// the cluster report requires the cloud admin permissions
+	if cluster, err := clusters.Get(limes, "current", clusters.GetOpts{}).Extract(); err != nil {
+		log.Printf("[WARN] Unable to retrieve Limes cluster overcommit factors: %s", err)
+		d.Set("overcommit_factor", map[string]float64{})
+	} else {
+		d.Set("overcommit_factor", limesOvercommitFactors(cluster))
 	}
 
 	d.Set("region", GetRegion(d, config))
@@ -119,7 +187,7 @@ func resourceCCloudDomainQuotaV1CreateOrUpdate(d *schema.ResourceData, meta inte
 				if d.HasChange(key) {
 					v := d.Get(key)
 					log.Printf("[QUOTA] Resource Changed: %s", key)
-					quota.Resources[resource] = limes.ValueWithUnit{Value: uint64(v.(float64)), Unit: unit}
+					quota.Resources[resource] = limes.ValueWithUnit{Value: uint64(v.(int)), Unit: unit}
 					log.Printf("[QUOTA] %s.%s: %s", service, resource, quota.Resources[resource].String())
 				}
 			}
@@ -127,6 +195,14 @@ func resourceCCloudDomainQuotaV1CreateOrUpdate(d *schema.ResourceData, meta inte
 		}
 	}
 
+	if limesQuotaRequestHasAliases(services) {
+		quota, err := domains.Get(client, domainID, domains.GetOpts{}).Extract()
+		if err != nil {
+			return fmt.Errorf("Error getting Limes domain: %s", err)
+		}
+		services = limesAliasQuotaRequest(services, func(s string) bool { _, ok := quota.Services[s]; return ok })
+	}
+
 	opts := domains.UpdateOpts{Services: services}
 	err = domains.Update(client, domainID, opts).ExtractErr()
 	if err != nil {
@@ -0,0 +1,193 @@
+package ccloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestResourceCCloudDomainQuotaV1ReadProjects(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		expected []interface{}
+	}{
+		{
+			"two projects",
+			http.StatusOK,
+			[]interface{}{
+				map[string]interface{}{
+					"project_id": "p1",
+					"name":       "project1",
+					"quota":      map[string]interface{}{"compute/cores": 10, "volumev2/capacity": 100},
+				},
+				map[string]interface{}{
+					"project_id": "p2",
+					"name":       "project2",
+					"quota":      map[string]interface{}{"compute/cores": 20},
+				},
+			},
+		},
+		{
+			"forbidden project list",
+			http.StatusForbidden,
+			[]interface{}{},
+		},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/v1/domains/d1":
+				w.Write([]byte(`{"domain":{"id":"d1","services":[{"type":"compute","area":"compute","resources":[` +
+					`{"name":"cores","quota":100,"projects_quota":30,"usage":5}]}]}}`))
+			case "/v1/domains/d1/projects":
+				if c.status != http.StatusOK {
+					w.WriteHeader(c.status)
+					return
+				}
+				// the projects are not sorted in the report
+				w.Write([]byte(`{"projects":[` +
+					`{"id":"p2","name":"project2","services":[{"type":"compute","resources":[{"name":"cores","quota":20}]}]},` +
+					`{"id":"p1","name":"project1","services":[{"type":"compute","resources":[{"name":"cores","quota":10}]},` +
+					`{"type":"volumev3","resources":[{"name":"capacity","unit":"GiB","quota":100}]}]}]}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		d := schema.TestResourceDataRaw(t, resourceCCloudDomainQuotaV1().Schema, map[string]interface{}{
+			"domain_id": "d1",
+		})
+		d.SetId("d1")
+		err := resourceCCloudDomainQuotaV1Read(d, testConfig(server))
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+
+		if v := d.Get("projects"); !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: expected %v projects, got %v", c.name, c.expected, v)
+		}
+		if v := d.Get("compute.0.cores"); v != 100 {
+			t.Errorf("%s: expected 100 cores, got %v", c.name, v)
+		}
+	}
+}
+
+func TestResourceCCloudDomainQuotaV1ReadOvercommitFactor(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		expected map[string]interface{}
+	}{
+		{
+			// the resources without the raw capacity are not overcommitted
+			"cluster report",
+			http.StatusOK,
+			map[string]interface{}{"compute/cores": 4.0, "compute/ram": 1.5},
+		},
+		{
+			"forbidden cluster report",
+			http.StatusForbidden,
+			map[string]interface{}{},
+		},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/v1/domains/d1":
+				w.Write([]byte(`{"domain":{"id":"d1","services":[]}}`))
+			case "/v1/clusters/current":
+				if c.status != http.StatusOK {
+					w.WriteHeader(c.status)
+					return
+				}
+				w.Write([]byte(`{"cluster":{"id":"current","services":[{"type":"compute","resources":[` +
+					`{"name":"cores","capacity":400,"raw_capacity":100,"usage":0},` +
+					`{"name":"ram","unit":"MiB","capacity":3072,"raw_capacity":2048,"usage":0},` +
+					`{"name":"instances","capacity":1000,"usage":0},` +
+					`{"name":"server_groups","capacity":10,"raw_capacity":0,"usage":0}]}]}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		d := schema.TestResourceDataRaw(t, resourceCCloudDomainQuotaV1().Schema, map[string]interface{}{
+			"domain_id": "d1",
+		})
+		d.SetId("d1")
+		err := resourceCCloudDomainQuotaV1Read(d, testConfig(server))
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+
+		if v := d.Get("overcommit_factor"); !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: expected %v overcommit factors, got %v", c.name, c.expected, v)
+		}
+	}
+}
+
+func TestResourceCCloudDomainQuotaV1ImportState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/domains/d1" && r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusAccepted)
+		case r.URL.Path == "/v1/domains/d1":
+			// the unknown service is skipped
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"domain":{"id":"d1","services":[` +
+				`{"type":"compute","area":"compute","resources":[{"name":"cores","quota":100,"projects_quota":30,"usage":5}]},` +
+				`{"type":"network","area":"network","resources":[{"name":"floating_ips","quota":10,"projects_quota":2,"usage":1}]},` +
+				`{"type":"unknown","area":"unknown","resources":[{"name":"things","quota":1}]}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := Provider().(*schema.Provider)
+	p.ConfigureFunc = func(*schema.ResourceData) (interface{}, error) {
+		return testConfig(server), nil
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: map[string]terraform.ResourceProvider{"ccloud": p},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "ccloud_domain_quota_v1" "quota" {
+  domain_id = "d1"
+
+  compute {
+    cores = 100
+  }
+
+  network {
+    floating_ips = 10
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ccloud_domain_quota_v1.quota", "compute.0.cores", "100"),
+					resource.TestCheckResourceAttr("ccloud_domain_quota_v1.quota", "network.0.floating_ips", "10"),
+				),
+			},
+			{
+				ResourceName:      "ccloud_domain_quota_v1.quota",
+				ImportState:       true,
+				ImportStateId:     "d1",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
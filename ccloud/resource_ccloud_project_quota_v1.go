@@ -1,6 +1,7 @@
 package ccloud
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/gophercloud/gophercloud"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sapcc/gophercloud-sapcc/resources/v1/domains"
 	"github.com/sapcc/gophercloud-sapcc/resources/v1/projects"
 	"github.com/sapcc/limes"
 )
@@ -26,8 +28,11 @@ func resourceCCloudProjectQuotaV1() *schema.Resource {
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 		},
 
+		CustomizeDiff: resourceCCloudProjectQuotaV1CustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"region": {
 				Type:     schema.TypeString,
@@ -45,6 +50,144 @@ func resourceCCloudProjectQuotaV1() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+
+			"quota_json": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateLimesQuotaJSON,
+				StateFunc:    normalizeJSONString,
+			},
+
+			// the SDK maps cannot hold the nested maps, thus the keys are
+			// flat, e.g. "compute/cores"
+			"quota": {
+				Type:          schema.TypeMap,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeInt},
+				ValidateFunc:  validateLimesQuotaMap,
+				ConflictsWith: []string{"quota_json"},
+			},
+
+			"clamp_to_domain_max": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"managed_resources": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateLimesResourceKey,
+				},
+			},
+
+			"managed_services": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateLimesService,
+				},
+			},
+
+			"notify_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateURL,
+			},
+
+			"fail_on_negative_available": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"wait_for_domain_quota": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"ignore_usage_for": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateLimesResourceKey,
+				},
+			},
+
+			// computed per resource attributes, keyed by "service/resource"
+			"observed": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+
+			"editable": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeBool},
+			},
+
+			"usage_percent": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeFloat},
+			},
+
+			"available": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+
+			"usage": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+
+			"domain_headroom": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+
+			"effective_quota": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+
+			"physical_usage": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+
+			"scrape_error": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"domain_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"project_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"effective_request_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 
@@ -53,21 +196,33 @@ func resourceCCloudProjectQuotaV1() *schema.Resource {
 			Schema: make(map[string]*schema.Schema, len(resources)),
 		}
 
+		var keys []string
 		for resource := range resources {
 			elem.Schema[resource] = &schema.Schema{
-				Type:     schema.TypeFloat,
-				Required: false,
-				Optional: true,
-				Computed: true,
+				Type:          schema.TypeInt,
+				Required:      false,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{fmt.Sprintf("%s.0.reset", sanitize(service))},
 			}
+			keys = append(keys, fmt.Sprintf("%s.0.%s", sanitize(service), resource))
+		}
+
+		// sets all the service resources quota to zero
+		elem.Schema["reset"] = &schema.Schema{
+			Type:          schema.TypeBool,
+			Optional:      true,
+			Default:       false,
+			ConflictsWith: keys,
 		}
 
 		quotaResource.Schema[sanitize(service)] = &schema.Schema{
-			Type:     schema.TypeList,
-			Optional: true,
-			Computed: true,
-			Elem:     elem,
-			MaxItems: 1,
+			Type:          schema.TypeList,
+			Optional:      true,
+			Computed:      true,
+			Elem:          elem,
+			MaxItems:      1,
+			ConflictsWith: []string{"quota_json", "quota"},
 		}
 	}
 
@@ -88,19 +243,109 @@ func resourceCCloudProjectQuotaV1Read(d *schema.ResourceData, meta interface{})
 
 	quota, err := projects.Get(limes, domainID, projectID, projects.GetOpts{}).Extract()
 	if err != nil {
-		return fmt.Errorf("Error getting Limes project: %s", err)
+		// the project was deleted or is being deleted
+		return CheckDeleted(d, err, "Error getting Limes project")
 	}
 
+	exists := func(s string) bool { _, ok := quota.Services[s]; return ok }
+	editable := make(map[string]bool)
+	observed := make(map[string]int64)
+	usagePercent := make(map[string]float64)
+	available := make(map[string]int64)
+	usage := make(map[string]int64)
+	effectiveQuota := make(map[string]int64)
+	physicalUsage := make(map[string]int64)
+	scrapeError := make(map[string]string)
+	whitelist := limesManagedResources(d)
+	ignoreUsage := limesIgnoreUsageFor(d)
+	managedServices := limesManagedServices(d)
 	for service, resources := range limesServices {
+		managed := limesServiceManaged(d, service, managedServices, whitelist)
+		srv := quota.Services[limesServiceType(service, exists)]
+		if srv != nil && srv.ScrapedAt == nil {
+			// the usage values are not reliable until the first scrape
+			scrapeError[sanitize(service)] = "the service usage was not scraped yet"
+		}
 		res := make(map[string]*uint64)
-		for resource := range resources {
-			if quota.Services[service] == nil || quota.Services[service].Resources[resource] == nil {
+		for resource, unit := range resources {
+			if srv == nil || srv.Resources[resource] == nil {
+				continue
+			}
+			if whitelist != nil && !whitelist[limesResourceKey(service, resource)] {
+				// not whitelisted resources are not tracked
 				continue
 			}
-			res[resource] = quota.Services[service].Resources[resource].Quota
-			log.Printf("[DEBUG] %s.%s: %s", service, resource, toString(quota.Services[service].Resources[resource]))
+			res[resource] = limesQuotaValue(srv.Resources[resource], unit)
+			editable[limesResourceKey(service, resource)] = !srv.Resources[resource].ExternallyManaged
+			if v, ok := limesUsagePercent(srv.Resources[resource]); ok && !ignoreUsage[limesResourceKey(service, resource)] {
+				usagePercent[limesResourceKey(service, resource)] = v
+			}
+			usage[limesResourceKey(service, resource)] = int64(srv.Resources[resource].Usage)
+			if v := srv.Resources[resource].PhysicalUsage; v != nil {
+				physicalUsage[limesResourceKey(service, resource)] = int64(*v)
+			}
+			if v := limesEffectiveQuota(quota, srv.Resources[resource]); v != nil {
+				effectiveQuota[limesResourceKey(service, resource)] = int64(*v)
+			}
+			if q := srv.Resources[resource].Quota; q != nil {
+				available[limesResourceKey(service, resource)] = int64(*q) - int64(srv.Resources[resource].Usage)
+			}
+			if !managed && res[resource] != nil {
+				observed[limesResourceKey(service, resource)] = int64(*res[resource])
+			}
+			log.Printf("[DEBUG] %s.%s: %s", service, resource, toString(srv.Resources[resource]))
+		}
+		if limesDynamicServices[service] && srv != nil {
+			// region specific resources, which are not part of the schema
+			for resource, r := range srv.Resources {
+				if _, ok := resources[resource]; ok || r.Quota == nil {
+					continue
+				}
+				if whitelist != nil && !whitelist[limesResourceKey(service, resource)] {
+					continue
+				}
+				observed[limesResourceKey(service, resource)] = int64(*r.Quota)
+			}
 		}
-		d.Set(sanitize(service), []map[string]*uint64{res})
+		if managed {
+			block := map[string]interface{}{
+				"reset": d.Get(fmt.Sprintf("%s.0.reset", sanitize(service))),
+			}
+			for resource, v := range res {
+				if v != nil {
+					block[resource] = int64(*v)
+				}
+			}
+			d.Set(sanitize(service), []map[string]interface{}{block})
+		} else if managedServices != nil || whitelist != nil {
+			// drop the services, which were tracked by the previous versions
+			// or are no longer whitelisted
+			d.Set(sanitize(service), []map[string]interface{}{})
+		}
+	}
+	d.Set("editable", editable)
+	d.Set("observed", observed)
+	d.Set("usage_percent", usagePercent)
+	d.Set("available", available)
+	d.Set("usage", usage)
+	d.Set("effective_quota", effectiveQuota)
+	d.Set("physical_usage", physicalUsage)
+	d.Set("scrape_error", scrapeError)
+
+	// the domain quota may be not readable with the project scoped token
+	if domain, err := domains.Get(limes, domainID, domains.GetOpts{}).Extract(); err == nil {
+		d.Set("domain_headroom", limesDomainHeadroom(domain))
+	} else {
+		log.Printf("[DEBUG] Unable to retrieve the Limes domain headroom: %s", err)
+		d.Set("domain_headroom", map[string]int64{})
+	}
+
+	d.Set("project_name", quota.Name)
+	d.Set("domain_name", "")
+	if identity, err := config.IdentityV3Client(GetRegion(d, config)); err == nil {
+		d.Set("domain_name", config.domainName(identity, domainID))
+	} else {
+		log.Printf("[DEBUG] Error creating OpenStack identity client: %s", err)
 	}
 
 	d.Set("region", GetRegion(d, config))
@@ -108,6 +353,52 @@ func resourceCCloudProjectQuotaV1Read(d *schema.ResourceData, meta interface{})
 	return nil
 }
 
+func resourceCCloudProjectQuotaV1CustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	// the request is rebuilt, when the quota is changed
+	changed := d.HasChange("quota_json") || d.HasChange("quota")
+	for service := range limesServices {
+		changed = changed || d.HasChange(sanitize(service))
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := d.SetNewComputed("effective_request_json"); err != nil {
+		return err
+	}
+
+	// the domain headroom is informational, failures don't affect the plan
+	before, after := limesDiffQuotaRequests(d)
+	if !limesQuotaIncreased(before, after) {
+		return nil
+	}
+
+	config := meta.(*Config)
+	region := config.Region
+	if v, ok := d.GetOk("region"); ok {
+		region = v.(string)
+	}
+	limes, err := config.limesV1Client(region)
+	if err != nil {
+		log.Printf("[WARN] Unable to compute the Limes domain headroom: %s", err)
+		return nil
+	}
+	domain, err := domains.Get(limes, d.Get("domain_id").(string), domains.GetOpts{}).Extract()
+	if err != nil {
+		log.Printf("[WARN] Unable to compute the Limes domain headroom: %s", err)
+		return nil
+	}
+
+	headroom := limesDomainHeadroom(domain)
+	limesPlanDomainHeadroom(headroom, domain, before, after)
+
+	return d.SetNew("domain_headroom", headroom)
+}
+
 func resourceCCloudProjectQuotaV1CreateOrUpdate(d *schema.ResourceData, meta interface{}) error {
 	domainID := d.Get("domain_id").(string)
 	projectID := d.Get("project_id").(string)
@@ -121,19 +412,74 @@ func resourceCCloudProjectQuotaV1CreateOrUpdate(d *schema.ResourceData, meta int
 		return fmt.Errorf("Error creating OpenStack limes client: %s", err)
 	}
 
+	if v, ok := d.GetOk("quota_json"); ok && d.HasChange("quota_json") {
+		log.Printf("[DEBUG] Quota JSON Changed")
+
+		services, err = expandLimesQuotaJSON(v.(string))
+		if err != nil {
+			return err
+		}
+	}
+
+	if v, ok := d.GetOk("quota"); ok && d.HasChange("quota") {
+		log.Printf("[DEBUG] Quota Map Changed")
+
+		services, err = expandLimesQuotaMap(v.(map[string]interface{}))
+		if err != nil {
+			return err
+		}
+	}
+
+	whitelist := limesManagedResources(d)
+	if whitelist != nil {
+		for service, srv := range services {
+			for resource := range srv.Resources {
+				if !whitelist[limesResourceKey(service, resource)] {
+					return fmt.Errorf("Error updating Limes project: %s quota is not listed in the managed_resources", limesResourceKey(service, resource))
+				}
+			}
+		}
+	}
+
+	if managedServices := limesManagedServices(d); managedServices != nil {
+		for service := range services {
+			if v, ok := limesServiceByName(service); ok && !managedServices[v] {
+				return fmt.Errorf("Error updating Limes project: %s service is not listed in the managed_services", service)
+			}
+		}
+		for service := range limesServices {
+			if _, ok := d.GetOk(sanitize(service)); ok && d.HasChange(sanitize(service)) && !managedServices[service] {
+				return fmt.Errorf("Error updating Limes project: %s service is not listed in the managed_services", service)
+			}
+		}
+	}
+
+	resetKeys := make(map[string]bool)
 	for _service, resources := range limesServices {
 		service := sanitize(_service)
 		if _, ok := d.GetOk(service); ok && d.HasChange(service) {
 			log.Printf("[DEBUG] Service Changed: %s", service)
 
 			quota := limes.ServiceQuotaRequest{Resources: make(limes.ResourceQuotaRequest)}
+			reset := d.Get(fmt.Sprintf("%s.0.reset", service)).(bool)
 			for resource, unit := range resources {
 				key := fmt.Sprintf("%s.0.%s", service, resource)
 
+				if whitelist != nil && !whitelist[limesResourceKey(_service, resource)] {
+					continue
+				}
+
+				if reset {
+					log.Printf("[DEBUG] Resetting Resource: %s", key)
+					quota.Resources[resource] = limes.ValueWithUnit{Value: 0, Unit: unit}
+					resetKeys[limesResourceKey(_service, resource)] = true
+					continue
+				}
+
 				if d.HasChange(key) {
 					v := d.Get(key)
 					log.Printf("[DEBUG] Resource Changed: %s", key)
-					quota.Resources[resource] = limes.ValueWithUnit{Value: uint64(v.(float64)), Unit: unit}
+					quota.Resources[resource] = limes.ValueWithUnit{Value: uint64(v.(int)), Unit: unit}
 					log.Printf("[DEBUG] %s.%s: %s", service, resource, quota.Resources[resource].String())
 				}
 			}
@@ -141,23 +487,130 @@ func resourceCCloudProjectQuotaV1CreateOrUpdate(d *schema.ResourceData, meta int
 		}
 	}
 
+	var timeout time.Duration
 	if d.Id() == "" {
 		// when the project was just created, it may not yet appeared in the limes
-		if err := limesCCloudProjectQuotaV1WaitForProject(client, domainID, projectID, &services, d.Timeout(schema.TimeoutCreate)); err != nil {
-			return err
+		timeout = d.Timeout(schema.TimeoutCreate)
+	}
+	quota, err := limesCCloudProjectQuotaV1WaitForProject(client, config.limesIdentityClient(GetRegion(d, config)), domainID, projectID, &services, timeout)
+	if err != nil {
+		return err
+	}
+
+	exists := func(s string) bool { _, ok := quota.Services[s]; return ok }
+	for service, srv := range services {
+		rs := quota.Services[limesServiceType(service, exists)]
+		for resource := range srv.Resources {
+			if !resetKeys[limesResourceKey(service, resource)] {
+				continue
+			}
+			// reset only the resources, which exist and can be changed
+			if rs == nil || rs.Resources[resource] == nil || rs.Resources[resource].ExternallyManaged {
+				delete(srv.Resources, resource)
+			}
 		}
 	}
 
+	services = limesAliasQuotaRequest(services, exists)
+
+	if err := limesResolveDynamicResources(services, quota); err != nil {
+		return fmt.Errorf("Error updating Limes project: %s", err)
+	}
+
+	for service, srv := range services {
+		for resource := range srv.Resources {
+			if quota.Services[service] == nil || quota.Services[service].Resources[resource] == nil {
+				continue
+			}
+			if quota.Services[service].Resources[resource].ExternallyManaged {
+				return fmt.Errorf("Error updating Limes project: %s quota is managed externally and cannot be changed", limesResourceKey(service, resource))
+			}
+		}
+	}
+
+	if d.Get("fail_on_negative_available").(bool) {
+		if err := limesCheckQuotaBelowUsage(services, quota, limesIgnoreUsageFor(d)); err != nil {
+			return fmt.Errorf("Error updating Limes project: %s", err)
+		}
+	}
+
+	if d.Get("clamp_to_domain_max").(bool) {
+		domainQuota, err := domains.Get(client, domainID, domains.GetOpts{}).Extract()
+		if err != nil {
+			return fmt.Errorf("Error getting Limes domain: %s", err)
+		}
+		limesClampQuotaRequest(services, quota, domainQuota)
+	}
+
+	limesCheckQuotaRatios(services, quota)
+
 	opts := projects.UpdateOpts{Services: services}
-	warn, err := projects.Update(client, domainID, projectID, opts).Extract()
+	_, body, err := opts.ToProjectUpdateMap()
 	if err != nil {
-		if err, ok := err.(gophercloud.ErrDefault400); ok {
-			return fmt.Errorf("Error updating Limes project: %s: %s", err.Body, err)
+		return fmt.Errorf("Error building Limes project update request: %s", err)
+	}
+	request, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("Error marshalling Limes project update request: %s", err)
+	}
+
+	var updateTimeout time.Duration
+	if config.WaitForMaintenance {
+		updateTimeout = d.Timeout(schema.TimeoutUpdate)
+		if d.Id() == "" {
+			updateTimeout = d.Timeout(schema.TimeoutCreate)
+		}
+	}
+
+	var domainTimeout time.Duration
+	if d.Get("wait_for_domain_quota").(bool) {
+		domainTimeout = d.Timeout(schema.TimeoutUpdate)
+		if d.Id() == "" {
+			domainTimeout = d.Timeout(schema.TimeoutCreate)
 		}
-		return fmt.Errorf("Error updating Limes project: %s", err)
 	}
-	if warn != nil {
-		log.Printf("[DEBUG] %s", string(warn))
+
+	// apply the quota increases before the decreases to avoid a transient
+	// inconsistency between the dependent resources
+	for _, services := range limesSplitQuotaRequest(services, quota) {
+		warn, err := limesCCloudProjectQuotaV1UpdateWaitForDomainQuota(client, domainID, projectID, services, updateTimeout, domainTimeout)
+		if err != nil {
+			if err, ok := err.(gophercloud.ErrDefault400); ok {
+				return fmt.Errorf("Error updating Limes project: %s: %s", err.Body, err)
+			}
+			if err, ok := err.(gophercloud.ErrDefault409); ok {
+				if errs := limesParseDomainQuotaExceeded(err.Body, services); len(errs) > 0 {
+					var msgs []string
+					for _, err := range errs {
+						msgs = append(msgs, err.Error())
+					}
+					return fmt.Errorf("Error updating Limes project: %s", strings.Join(msgs, "; "))
+				}
+				return fmt.Errorf("Error updating Limes project: %s: %s", err.Body, err)
+			}
+			return fmt.Errorf("Error updating Limes project: %s", err)
+		}
+		if warn != nil {
+			log.Printf("[DEBUG] %s", string(warn))
+		}
+	}
+
+	// the request is recorded only, when it was applied
+	d.Set("effective_request_json", string(request))
+
+	// Limes may adjust the requested quota, e.g. cap it to the domain quota
+	applied, err := projects.Get(client, domainID, projectID, projects.GetOpts{}).Extract()
+	if err != nil {
+		return fmt.Errorf("Error getting Limes project: %s", err)
+	}
+	limesCheckAppliedQuota(services, applied)
+
+	changes := limesQuotaChanges(services, quota, applied)
+	if v := d.Get("notify_url").(string); v != "" {
+		limesNotifyQuotaChange(v, domainID, projectID, changes)
+	}
+	if config.TelemetryEndpoint != "" {
+		limesEmitQuotaMetrics(config.TelemetryEndpoint, changes)
 	}
 
 	log.Printf("[DEBUG] Resulting Quota for: %s/%s", domainID, projectID)
@@ -173,8 +626,14 @@ func resourceCCloudProjectQuotaV1Delete(d *schema.ResourceData, meta interface{}
 }
 
 func resourceCCloudProjectQuotaV1Import(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	parts := strings.SplitN(d.Id(), "/", 2)
-	if len(parts) != 2 {
+	// the extra projects would be imported into the addresses, which don't
+	// match any configuration block, and the next plan would destroy them
+	if strings.Contains(d.Id(), ",") {
+		return nil, fmt.Errorf("Only a single project quota can be imported at once, import each project into its own resource address")
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(d.Id()), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
 		err := fmt.Errorf("Invalid format specified for Quota. Format must be <domain id>/<project id>")
 		return nil, err
 	}
@@ -183,5 +642,10 @@ func resourceCCloudProjectQuotaV1Import(d *schema.ResourceData, meta interface{}
 	d.Set("domain_id", parts[0])
 	d.Set("project_id", parts[1])
 
+	// manage all the services of the imported project
+	for service := range limesServices {
+		d.Set(sanitize(service), []map[string]interface{}{{}})
+	}
+
 	return []*schema.ResourceData{d}, nil
 }
@@ -0,0 +1,598 @@
+package ccloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/sapcc/limes"
+)
+
+// testConfig returns the provider config, which uses the mocked server for
+// all the service catalog endpoints.
+func testConfig(server *httptest.Server) *Config {
+	config := &Config{}
+	config.OsClient = &gophercloud.ProviderClient{
+		HTTPClient: *server.Client(),
+		EndpointLocator: func(gophercloud.EndpointOpts) (string, error) {
+			return server.URL + "/", nil
+		},
+	}
+
+	return config
+}
+
+func TestResourceCCloudProjectQuotaV1Import(t *testing.T) {
+	p := Provider().(*schema.Provider)
+	info := &terraform.InstanceInfo{Type: "ccloud_project_quota_v1"}
+
+	cases := []struct {
+		id       string
+		expected [][2]string
+	}{
+		{"d1/p1", [][2]string{{"d1", "p1"}}},
+		{" d1/p1 ", [][2]string{{"d1", "p1"}}},
+		{"d1/p1,d2/p2", nil},
+		{"d1/p1, d1/p2 ,d2/p3", nil},
+		{"", nil},
+		{"p1", nil},
+		{"d1/", nil},
+		{"/p1", nil},
+		{"d1/p1,", nil},
+		{"d1/p1,p2", nil},
+	}
+
+	for _, c := range cases {
+		states, err := p.ImportState(info, c.id)
+		if c.expected == nil {
+			if err == nil {
+				t.Errorf("%q: expected an error", c.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.id, err)
+			continue
+		}
+
+		var ids [][2]string
+		for _, s := range states {
+			if s.ID != s.Attributes["project_id"] {
+				t.Errorf("%q: expected %q ID, got %q", c.id, s.Attributes["project_id"], s.ID)
+			}
+			// all the services are managed by the imported resource
+			if s.Attributes["compute.#"] != "1" || s.Attributes["network.#"] != "1" {
+				t.Errorf("%q: expected the services to be managed, got %v", c.id, s.Attributes)
+			}
+			ids = append(ids, [2]string{s.Attributes["domain_id"], s.Attributes["project_id"]})
+		}
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.id, c.expected, ids)
+		}
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1Read(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","name":"project","services":[{"type":"compute","area":"compute","scraped_at":1,"resources":[` +
+			`{"name":"cores","quota":10,"usage":2},` +
+			`{"name":"ram","unit":"MiB","quota":2048,"usage":0,"externally_managed":true}]}]}}`))
+	}))
+	defer server.Close()
+
+	config := testConfig(server)
+
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		editable map[string]interface{}
+		usage    map[string]interface{}
+	}{
+		{
+			"all resources",
+			map[string]interface{}{"compute": []interface{}{map[string]interface{}{"cores": 1}}},
+			map[string]interface{}{"compute/cores": true, "compute/ram": false},
+			map[string]interface{}{"compute/cores": 2, "compute/ram": 0},
+		},
+		{
+			"managed resources",
+			map[string]interface{}{"managed_resources": []interface{}{"compute/cores"}},
+			map[string]interface{}{"compute/cores": true},
+			map[string]interface{}{"compute/cores": 2},
+		},
+	}
+
+	for _, c := range cases {
+		c.raw["domain_id"] = "d1"
+		c.raw["project_id"] = "p1"
+		d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, c.raw)
+		d.SetId("p1")
+
+		if err := resourceCCloudProjectQuotaV1Read(d, config); err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+		if v := d.Get("editable"); !reflect.DeepEqual(v, c.editable) {
+			t.Errorf("%s: expected %v editable, got %v", c.name, c.editable, v)
+		}
+		if v := d.Get("usage"); !reflect.DeepEqual(v, c.usage) {
+			t.Errorf("%s: expected %v usage, got %v", c.name, c.usage, v)
+		}
+		if v := d.Get("compute.0.cores"); v != 10 {
+			t.Errorf("%s: expected 10 cores, got %v", c.name, v)
+		}
+		if v := d.Get("project_name"); v != "project" {
+			t.Errorf("%s: expected \"project\" project name, got %v", c.name, v)
+		}
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1ReadLargeValues(t *testing.T) {
+	// 5 PiB + 1 byte, the Limes reports are decoded by gophercloud through
+	// float64, i.e. they are exact up to 2^53 bytes (8 PiB)
+	const capacity = 5<<50 + 1
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"project":{"id":"p1","services":[{"type":"object-store","area":"storage","scraped_at":1,"resources":[`+
+			`{"name":"capacity","unit":"B","quota":%d,"usage":%d}]}]}}`, uint64(capacity), uint64(capacity-2))
+	}))
+	defer server.Close()
+
+	raw := map[string]interface{}{
+		"domain_id":   "d1",
+		"project_id":  "p1",
+		"objectstore": []interface{}{map[string]interface{}{"capacity": capacity}},
+	}
+	d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, raw)
+	d.SetId("p1")
+
+	if err := resourceCCloudProjectQuotaV1Read(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := d.Get("objectstore.0.capacity"); v != capacity {
+		t.Errorf("expected %d capacity, got %v", uint64(capacity), v)
+	}
+	if v := d.Get("usage.objectstore/capacity"); v != capacity-2 {
+		t.Errorf("expected %d usage, got %v", uint64(capacity-2), v)
+	}
+	if v := d.Get("available.objectstore/capacity"); v != 2 {
+		t.Errorf("expected 2 available, got %v", v)
+	}
+
+	// the state value is applied back without the precision loss
+	services, err := expandLimesQuotaMap(map[string]interface{}{"object-store/capacity": d.Get("objectstore.0.capacity")})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := services["object-store"].Resources["capacity"]; v.Value != capacity || v.Unit != limes.UnitBytes {
+		t.Errorf("expected %d B, got %s", uint64(capacity), v.String())
+	}
+
+	// the requested values are not converted to float64 at all
+	services, err = expandLimesQuotaMap(map[string]interface{}{"object-store/capacity": "1152921504606846977"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := services["object-store"].Resources["capacity"]; v.Value != 1<<60+1 {
+		t.Errorf("expected %d B, got %s", uint64(1<<60+1), v.String())
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1UpdateReset(t *testing.T) {
+	var requests []map[string]map[string]uint64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPut {
+			var body struct {
+				Project struct {
+					Services []struct {
+						Type      string `json:"type"`
+						Resources []struct {
+							Name  string `json:"name"`
+							Quota uint64 `json:"quota"`
+						} `json:"resources"`
+					} `json:"services"`
+				} `json:"project"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected update request: %s", err)
+			}
+			request := make(map[string]map[string]uint64)
+			for _, srv := range body.Project.Services {
+				request[srv.Type] = make(map[string]uint64)
+				for _, res := range srv.Resources {
+					request[srv.Type][res.Name] = res.Quota
+				}
+			}
+			requests = append(requests, request)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","services":[` +
+			`{"type":"compute","area":"compute","scraped_at":1,"resources":[{"name":"cores","quota":10,"usage":0}]},` +
+			`{"type":"network","area":"network","scraped_at":1,"resources":[` +
+			`{"name":"floating_ips","quota":5,"usage":0},` +
+			`{"name":"routers","quota":2,"usage":0},` +
+			`{"name":"networks","quota":3,"usage":0,"externally_managed":true}]}]}}`))
+	}))
+	defer server.Close()
+
+	raw := map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+		"network":    []interface{}{map[string]interface{}{"reset": true}},
+	}
+	d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, raw)
+	d.SetId("p1")
+
+	if err := resourceCCloudProjectQuotaV1CreateOrUpdate(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// only the reported and editable network resources are reset, the other
+	// services are not sent at all
+	expected := []map[string]map[string]uint64{
+		{"network": {"floating_ips": 0, "routers": 0}},
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v update requests, got %v", expected, requests)
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1ReadManagedServices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","services":[` +
+			`{"type":"compute","area":"compute","scraped_at":1,"resources":[{"name":"cores","quota":10,"usage":0}]},` +
+			`{"type":"dns","area":"dns","scraped_at":1,"resources":[{"name":"zones","quota":3,"usage":0}]}]}}`))
+	}))
+	defer server.Close()
+
+	compute := []interface{}{map[string]interface{}{"cores": 1}}
+	dns := []interface{}{map[string]interface{}{"zones": 1}}
+
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		cores    interface{}
+		zones    interface{}
+		observed map[string]interface{}
+	}{
+		{
+			"configured service",
+			map[string]interface{}{"compute": compute},
+			10, nil,
+			map[string]interface{}{"dns/zones": 3},
+		},
+		{
+			// the state of the previous versions contains all the services
+			"managed services",
+			map[string]interface{}{"managed_services": []interface{}{"compute"}, "compute": compute, "dns": dns},
+			10, nil,
+			map[string]interface{}{"dns/zones": 3},
+		},
+		{
+			"managed services without the configured service",
+			map[string]interface{}{"managed_services": []interface{}{"dns"}, "compute": compute},
+			nil, 3,
+			map[string]interface{}{"compute/cores": 10},
+		},
+		{
+			// the dns service was whitelisted before
+			"managed resources without the whitelisted service",
+			map[string]interface{}{"managed_resources": []interface{}{"compute/cores"}, "compute": compute, "dns": dns},
+			10, nil,
+			map[string]interface{}{},
+		},
+	}
+
+	for _, c := range cases {
+		c.raw["domain_id"] = "d1"
+		c.raw["project_id"] = "p1"
+		d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, c.raw)
+		d.SetId("p1")
+
+		if err := resourceCCloudProjectQuotaV1Read(d, testConfig(server)); err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+		for k, expected := range map[string]interface{}{"compute": c.cores, "dns": c.zones} {
+			v := d.Get(k).([]interface{})
+			if expected == nil {
+				if len(v) != 0 {
+					t.Errorf("%s: expected the %s service to be not managed, got %v", c.name, k, v)
+				}
+				continue
+			}
+			if len(v) == 0 {
+				t.Errorf("%s: expected the %s service to be managed", c.name, k)
+				continue
+			}
+			if k == "compute" && v[0].(map[string]interface{})["cores"] != expected {
+				t.Errorf("%s: expected %v cores, got %v", c.name, expected, v[0])
+			}
+			if k == "dns" && v[0].(map[string]interface{})["zones"] != expected {
+				t.Errorf("%s: expected %v zones, got %v", c.name, expected, v[0])
+			}
+		}
+		if v := d.Get("observed"); !reflect.DeepEqual(v, c.observed) {
+			t.Errorf("%s: expected %v observed, got %v", c.name, c.observed, v)
+		}
+	}
+
+	// the services, which are not listed, cannot be configured
+	d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, map[string]interface{}{
+		"domain_id":        "d1",
+		"project_id":       "p1",
+		"managed_services": []interface{}{"compute"},
+		"dns":              dns,
+	})
+	d.SetId("p1")
+	err := resourceCCloudProjectQuotaV1CreateOrUpdate(d, testConfig(server))
+	if err == nil || err.Error() != "Error updating Limes project: dns service is not listed in the managed_services" {
+		t.Errorf("expected the not listed service error, got %v", err)
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1EffectiveRequestJSON(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPut {
+			var body bytes.Buffer
+			body.ReadFrom(r.Body)
+			requests = append(requests, body.String())
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","services":[` +
+			`{"type":"compute","area":"compute","scraped_at":1,"resources":[{"name":"cores","quota":10,"usage":0}]},` +
+			`{"type":"volumev3","area":"storage","scraped_at":1,"resources":[{"name":"capacity","unit":"GiB","quota":50,"usage":0}]}]}}`))
+	}))
+	defer server.Close()
+
+	// the volumev2 service is exposed as volumev3
+	raw := map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+		"compute":    []interface{}{map[string]interface{}{"cores": 20}},
+		"volumev2":   []interface{}{map[string]interface{}{"capacity": 100}},
+	}
+	d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, raw)
+	d.SetId("p1")
+
+	if err := resourceCCloudProjectQuotaV1CreateOrUpdate(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `{"project":{"Cluster":"","services":[` +
+		`{"rates":[],"resources":[{"name":"cores","quota":20,"unit":""}],"type":"compute"},` +
+		`{"rates":[],"resources":[{"name":"capacity","quota":100,"unit":"GiB"}],"type":"volumev3"}]}}`
+	if v := d.Get("effective_request_json"); v != expected {
+		t.Errorf("expected %s, got %s", expected, v)
+	}
+	if len(requests) != 1 || requests[0] != expected {
+		t.Errorf("expected the %s request to be sent, got %v", expected, requests)
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1UpdateCapped(t *testing.T) {
+	defer log.SetOutput(os.Stderr)
+
+	cores := 10
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPut {
+			// the backend caps the requested value to the domain quota
+			cores = 15
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"project":{"id":"p1","services":[{"type":"compute","area":"compute","scraped_at":1,"resources":[`+
+			`{"name":"cores","quota":%d,"usage":0}]}]}}`, cores)
+	}))
+	defer server.Close()
+
+	raw := map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+		"compute":    []interface{}{map[string]interface{}{"cores": 20}},
+	}
+	d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, raw)
+	d.SetId("p1")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	if err := resourceCCloudProjectQuotaV1CreateOrUpdate(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if v := d.Get("compute.0.cores"); v != 15 {
+		t.Errorf("expected the capped 15 cores in the state, got %v", v)
+	}
+	if expected := "[WARN] The applied compute/cores quota differs from the requested one: 20 -> 15"; !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected the %q warning, got %q", expected, buf.String())
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1ShareCapacityRoundTrip(t *testing.T) {
+	// the quota is stored in MiB, the share_capacity is reported in MiB and the
+	// snapshot_capacity in GiB, while the schema uses GiB for both
+	quota := map[string]uint64{"share_capacity": 10 << 10, "snapshot_capacity": 5 << 10}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPut {
+			var body struct {
+				Project struct {
+					Services limes.QuotaRequest `json:"services"`
+				} `json:"project"`
+			}
+			body.Project.Services = make(limes.QuotaRequest)
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected update request: %s", err)
+			}
+			for resource, v := range body.Project.Services["sharev2"].Resources {
+				v, err := v.ConvertTo(limes.UnitMebibytes)
+				if err != nil {
+					t.Errorf("unexpected %s unit: %s", resource, err)
+				}
+				quota[resource] = v.Value
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"project":{"id":"p1","services":[{"type":"sharev2","area":"storage","scraped_at":1,"resources":[`+
+			`{"name":"share_capacity","unit":"MiB","quota":%d,"usage":0},`+
+			`{"name":"snapshot_capacity","unit":"GiB","quota":%d,"usage":0},`+
+			`{"name":"share_networks","usage":0}]}]}}`, quota["share_capacity"], quota["snapshot_capacity"]>>10)
+	}))
+	defer server.Close()
+
+	config := testConfig(server)
+	raw := map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+		"sharev2":    []interface{}{map[string]interface{}{"share_capacity": 100, "snapshot_capacity": 50}},
+	}
+	resource := resourceCCloudProjectQuotaV1()
+
+	var state *terraform.InstanceState
+	for i := 1; i <= 2; i++ {
+		d := schema.TestResourceDataRaw(t, resource.Schema, raw)
+		d.SetId("p1")
+		if err := resourceCCloudProjectQuotaV1CreateOrUpdate(d, config); err != nil {
+			t.Fatalf("apply %d: unexpected error: %s", i, err)
+		}
+		for k, expected := range map[string]int{"share_capacity": 100, "snapshot_capacity": 50} {
+			if v := d.Get("sharev2.0." + k); v != expected {
+				t.Errorf("apply %d: expected %d GiB %s, got %v", i, expected, k, v)
+			}
+		}
+		// the resource without quota, i.e. unlimited, is read as zero
+		if v := d.Get("sharev2.0.share_networks"); v != 0 {
+			t.Errorf("apply %d: expected no share_networks quota, got %v", i, v)
+		}
+		state = d.State()
+	}
+	if quota["share_capacity"] != 100<<10 || quota["snapshot_capacity"] != 50<<10 {
+		t.Errorf("expected 100 GiB and 50 GiB, got %v MiB", quota)
+	}
+
+	// the second plan doesn't change the sharev2 quota
+	diff, err := resource.Diff(state, terraform.NewResourceConfigRaw(raw), config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// the unconfigured service blocks are marked as computed by the legacy
+	// diff, the sharev2 values must not differ
+	for k, v := range diff.Attributes {
+		if strings.HasPrefix(k, "sharev2.") {
+			t.Errorf("expected no %s diff, got %+v", k, v)
+		}
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1ReadPhysicalUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","services":[` +
+			`{"type":"compute","area":"compute","scraped_at":1,"resources":[{"name":"cores","quota":10,"usage":4}]},` +
+			`{"type":"sharev2","area":"storage","scraped_at":1,"resources":[` +
+			`{"name":"share_capacity","unit":"GiB","quota":100,"usage":80,"physical_usage":30}]}]}}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+	})
+	d.SetId("p1")
+	if err := resourceCCloudProjectQuotaV1Read(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{"compute/cores": 4, "sharev2/share_capacity": 80}
+	if v := d.Get("usage"); !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v usage, got %v", expected, v)
+	}
+	// the resources without the physical usage are not reported
+	expected = map[string]interface{}{"sharev2/share_capacity": 30}
+	if v := d.Get("physical_usage"); !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v physical usage, got %v", expected, v)
+	}
+}
+
+func TestResourceCCloudProjectQuotaV1ReadScrapeError(t *testing.T) {
+	// the network service was never scraped successfully
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"project":{"id":"p1","services":[` +
+			`{"type":"compute","area":"compute","scraped_at":1,"resources":[{"name":"cores","quota":10,"usage":4}]},` +
+			`{"type":"network","area":"network","resources":[{"name":"networks","quota":5,"usage":0}]}]}}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceCCloudProjectQuotaV1().Schema, map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+	})
+	d.SetId("p1")
+	if err := resourceCCloudProjectQuotaV1Read(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{"network": "the service usage was not scraped yet"}
+	if v := d.Get("scrape_error"); !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v scrape errors, got %v", expected, v)
+	}
+	// the quota of the errored service is still read
+	if v := d.Get("observed.network/networks"); v != 5 {
+		t.Errorf("expected 5 networks, got %v", v)
+	}
+}
@@ -0,0 +1,114 @@
+package ccloud
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sapcc/gophercloud-sapcc/resources/v1/projects"
+	"github.com/sapcc/limes"
+)
+
+func resourceCCloudQuotaProjectReadyV1() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceCCloudQuotaProjectReadyV1Create,
+		Read:   resourceCCloudQuotaProjectReadyV1Read,
+		Delete: schema.RemoveFromState,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"domain_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"project_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"ready": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// limesAllServices returns a request, which contains all known services and
+// their aliases. It is used to wait for the whole project report.
+func limesAllServices() *limes.QuotaRequest {
+	services := make(limes.QuotaRequest)
+	for service := range limesServices {
+		services[service] = limes.ServiceQuotaRequest{}
+		for _, alias := range limesServiceAliases[service] {
+			services[alias] = limes.ServiceQuotaRequest{}
+		}
+	}
+	return &services
+}
+
+func resourceCCloudQuotaProjectReadyV1Create(d *schema.ResourceData, meta interface{}) error {
+	domainID := d.Get("domain_id").(string)
+	projectID := d.Get("project_id").(string)
+
+	config := meta.(*Config)
+	client, err := config.limesV1Client(GetRegion(d, config))
+	if err != nil {
+		return fmt.Errorf("Error creating OpenStack limes client: %s", err)
+	}
+
+	log.Printf("[DEBUG] Waiting for Limes project %s/%s to be initialized", domainID, projectID)
+
+	_, err = limesCCloudProjectQuotaV1WaitForProject(client, config.limesIdentityClient(GetRegion(d, config)), domainID, projectID, limesAllServices(), d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(projectID)
+
+	return resourceCCloudQuotaProjectReadyV1Read(d, meta)
+}
+
+func resourceCCloudQuotaProjectReadyV1Read(d *schema.ResourceData, meta interface{}) error {
+	domainID := d.Get("domain_id").(string)
+	projectID := d.Get("project_id").(string)
+
+	config := meta.(*Config)
+	client, err := config.limesV1Client(GetRegion(d, config))
+	if err != nil {
+		return fmt.Errorf("Error creating OpenStack limes client: %s", err)
+	}
+
+	quota, err := projects.Get(client, domainID, projectID, projects.GetOpts{}).Extract()
+	if err != nil {
+		return CheckDeleted(d, err, "Error getting Limes project")
+	}
+
+	ready := true
+	for _, service := range quota.Services {
+		if len(service.Resources) == 0 {
+			ready = false
+			break
+		}
+	}
+
+	d.Set("ready", ready)
+
+	d.Set("region", GetRegion(d, config))
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package ccloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestResourceCCloudQuotaProjectReadyV1Create(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/d1/projects/p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			// the project report is not yet initialized
+			w.Write([]byte(`{"project":{"id":"p1","services":[{"type":"compute","resources":[]}]}}`))
+			return
+		}
+		w.Write([]byte(`{"project":{"id":"p1","services":[{"type":"compute","resources":[{"name":"cores","quota":0}]}]}}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceCCloudQuotaProjectReadyV1().Schema, map[string]interface{}{
+		"domain_id":  "d1",
+		"project_id": "p1",
+	})
+	if err := resourceCCloudQuotaProjectReadyV1Create(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the initial check, the retried wait and the final read
+	if calls != 3 {
+		t.Errorf("expected 3 requests, got %d", calls)
+	}
+	if d.Id() != "p1" {
+		t.Errorf("expected \"p1\" ID, got %q", d.Id())
+	}
+	if v := d.Get("ready"); v != true {
+		t.Errorf("expected the project to be ready, got %v", v)
+	}
+
+	// the report may become incomplete again, e.g. after a new service
+	calls = 0
+	if err := resourceCCloudQuotaProjectReadyV1Read(d, testConfig(server)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := d.Get("ready"); v != false {
+		t.Errorf("expected the project to be not ready, got %v", v)
+	}
+}
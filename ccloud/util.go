@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-openapi/validate"
 	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/pagination"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
 	"github.com/sapcc/kubernikus/pkg/api/models"
@@ -61,6 +64,22 @@ func expandToMapStringString(v map[string]interface{}) map[string]string {
 	return m
 }
 
+// mergeDefaultTags merges the provider-level default tags with the resource
+// tags. The resource tags take precedence over the default tags.
+func mergeDefaultTags(defaultTags map[string]string, tags interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, v := range defaultTags {
+		m[k] = v
+	}
+	if v, ok := tags.(map[string]interface{}); ok {
+		for k, v := range v {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
 func expandToStringSlice(v []interface{}) []string {
 	s := make([]string, len(v))
 	for i, val := range v {
@@ -170,3 +189,71 @@ func validateKubernetesVersion(v interface{}, k string) ([]string, []error) {
 
 	return nil, nil
 }
+
+// truncateLog returns the last max bytes of the log. The cut is moved forward
+// to the rune boundary to keep the result valid UTF-8. Zero max disables the
+// truncation.
+func truncateLog(log string, max int) string {
+	if max <= 0 || len(log) <= max {
+		return log
+	}
+
+	i := len(log) - max
+	for i < len(log) && !utf8.RuneStart(log[i]) {
+		i++
+	}
+
+	return log[i:]
+}
+
+// listPages traverses the pager and calls the extract function for each page.
+// The extract function returns the number of the extracted items. The
+// traversal stops, when the limit is reached. Zero limit means no limit.
+// The single item lookups, which must check all the items, use AllPages.
+func listPages(pager pagination.Pager, limit int, extract func(pagination.Page) (int, error)) error {
+	var count int
+	return pager.EachPage(func(page pagination.Page) (bool, error) {
+		n, err := extract(page)
+		if err != nil {
+			return false, err
+		}
+		count += n
+		return limit == 0 || count < limit, nil
+	})
+}
+
+// defaultParallelRequests is the default amount of the concurrent requests,
+// when max_parallel_requests is not set.
+const defaultParallelRequests = 10
+
+// forEachConcurrently calls the function for each ID and returns the errors
+// indexed by the ID. The limit bounds the amount of the concurrent calls.
+func forEachConcurrently(ids []string, limit int, f func(string) error) map[string]error {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	errors := make(map[string]error)
+
+	if limit <= 0 {
+		limit = defaultParallelRequests
+	}
+	sem := make(chan struct{}, limit)
+
+	for _, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := f(id); err != nil {
+				mutex.Lock()
+				errors[id] = err
+				mutex.Unlock()
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	return errors
+}
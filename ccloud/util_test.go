@@ -0,0 +1,145 @@
+package ccloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gophercloud/gophercloud/pagination"
+)
+
+func TestTruncateLog(t *testing.T) {
+	cases := []struct {
+		log      string
+		max      int
+		expected string
+	}{
+		{"hello world", 0, "hello world"},
+		{"hello world", 20, "hello world"},
+		{"hello world", 5, "world"},
+		// "ü" is encoded as two bytes, the partial rune is dropped
+		{"grüße", 5, "üße"},
+		{"grüße", 4, "ße"},
+		{"grüße", 3, "ße"},
+		{"grüße", 2, "e"},
+		{"日本語", 4, "語"},
+	}
+
+	for _, c := range cases {
+		v := truncateLog(c.log, c.max)
+		if v != c.expected {
+			t.Errorf("truncateLog(%q, %d): expected %q, got %q", c.log, c.max, c.expected, v)
+		}
+		if !utf8.ValidString(v) {
+			t.Errorf("truncateLog(%q, %d): invalid UTF-8 %q", c.log, c.max, v)
+		}
+	}
+}
+
+type testPage struct {
+	pagination.LinkedPageBase
+}
+
+func (p testPage) IsEmpty() (bool, error) {
+	items, err := extractTestPageItems(p)
+	return len(items) == 0, err
+}
+
+func extractTestPageItems(p pagination.Page) ([]int, error) {
+	var s struct {
+		Items []int `json:"items"`
+	}
+	err := p.(testPage).ExtractInto(&s)
+	return s.Items, err
+}
+
+func TestListPages(t *testing.T) {
+	var requests int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		body := map[string]interface{}{
+			"items": []int{page*2 + 1, page*2 + 2},
+			"links": map[string]interface{}{},
+		}
+		if page < 2 {
+			body["links"] = map[string]interface{}{"next": fmt.Sprintf("%s/items?page=%d", server.URL, page+1)}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	client := testServiceClient(server)
+
+	cases := []struct {
+		limit    int
+		expected []int
+		requests int
+	}{
+		{0, []int{1, 2, 3, 4, 5, 6}, 3},
+		{3, []int{1, 2, 3, 4}, 2},
+		{4, []int{1, 2, 3, 4}, 2},
+		{1, []int{1, 2}, 1},
+	}
+
+	for _, c := range cases {
+		requests = 0
+		pager := pagination.NewPager(client, server.URL+"/items?page=0", func(r pagination.PageResult) pagination.Page {
+			return testPage{pagination.LinkedPageBase{PageResult: r}}
+		})
+
+		var items []int
+		err := listPages(pager, c.limit, func(page pagination.Page) (int, error) {
+			v, err := extractTestPageItems(page)
+			items = append(items, v...)
+			return len(v), err
+		})
+		if err != nil {
+			t.Fatalf("limit %d: unexpected error: %s", c.limit, err)
+		}
+		if !reflect.DeepEqual(items, c.expected) {
+			t.Errorf("limit %d: expected %v items, got %v", c.limit, c.expected, items)
+		}
+		if requests != c.requests {
+			t.Errorf("limit %d: expected %d requests, got %d", c.limit, c.requests, requests)
+		}
+	}
+}
+
+func TestMergeDefaultTags(t *testing.T) {
+	cases := []struct {
+		name        string
+		defaultTags map[string]string
+		tags        interface{}
+		expected    map[string]interface{}
+	}{
+		{"empty", nil, nil, map[string]interface{}{}},
+		{"defaults only", map[string]string{"env": "prod"}, nil, map[string]interface{}{"env": "prod"}},
+		{"tags only", nil, map[string]interface{}{"app": "web"}, map[string]interface{}{"app": "web"}},
+		{
+			"merged",
+			map[string]string{"env": "prod", "team": "a"},
+			map[string]interface{}{"app": "web"},
+			map[string]interface{}{"env": "prod", "team": "a", "app": "web"},
+		},
+		{
+			"tags take precedence",
+			map[string]string{"env": "prod", "team": "a"},
+			map[string]interface{}{"env": "dev"},
+			map[string]interface{}{"env": "dev", "team": "a"},
+		},
+	}
+
+	for _, c := range cases {
+		if v := mergeDefaultTags(c.defaultTags, c.tags); !reflect.DeepEqual(v, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, v)
+		}
+	}
+}
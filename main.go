@@ -5,7 +5,16 @@ import (
 	"github.com/sapcc/terraform-provider-ccloud/ccloud"
 )
 
+// these variables are set during the release build
+var (
+	version = "dev"
+	commit  = ""
+)
+
 func main() {
+	ccloud.ProviderVersion = version
+	ccloud.ProviderCommit = commit
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: ccloud.Provider})
 }